@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"valo-track/internal/api/fake"
+)
+
+func main() {
+	// Parsear argumentos de línea de comandos
+	addr := flag.String("addr", "localhost:8089", "Dirección donde escuchar")
+	fixturesDir := flag.String("fixtures", "match", "Directorio de fixtures JSON")
+	apiKey := flag.String("key", "", "API key exigida en el header Authorization (vacío = sin validar)")
+	rateLimit := flag.Int("rate", 30, "Requests por minuto antes de responder 429 (0 = sin límite)")
+	retryAfter := flag.Int("retry-after", 0, "Segundos del header Retry-After (0 = calculado desde la ventana)")
+	errorRate := flag.Float64("error-rate", 0, "Probabilidad de responder 5xx (0-1)")
+	notFoundRate := flag.Float64("notfound-rate", 0, "Probabilidad de responder 404 (0-1)")
+	latency := flag.Duration("latency", 0, "Demora fija por request (ej: 300ms)")
+	jitter := flag.Duration("jitter", 0, "Demora aleatoria adicional máxima (ej: 2s)")
+	seed := flag.Int64("seed", 0, "Semilla para fallos reproducibles (0 = aleatoria)")
+	flag.Parse()
+
+	server, err := fake.NewServer(fake.Options{
+		FixturesDir:  *fixturesDir,
+		APIKey:       *apiKey,
+		RateLimit:    *rateLimit,
+		RetryAfter:   *retryAfter,
+		ErrorRate:    *errorRate,
+		NotFoundRate: *notFoundRate,
+		Latency:      *latency,
+		Jitter:       *jitter,
+		Seed:         *seed,
+		Logger:       log.New(os.Stdout, "[fake-henrik] ", log.LstdFlags),
+	})
+	if err != nil {
+		log.Fatalf("Error creando servidor: %v", err)
+	}
+
+	fmt.Printf("=== FAKE HENRIKDEV API ===\n")
+	fmt.Printf("Fixtures: %s | Rate limit: %d/min | 5xx: %.0f%% | 404: %.0f%%\n",
+		*fixturesDir, *rateLimit, *errorRate*100, *notFoundRate*100)
+	fmt.Printf("Usar con: VALO_API_BASE_URL=http://%s/valorant\n\n", *addr)
+
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
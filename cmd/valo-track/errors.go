@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"valo-track/internal/api"
+)
+
+// Códigos de salida según la clase de error de la API (2 lo usa flag para errores de uso)
+const (
+	exitError        = 1
+	exitUnauthorized = 3
+	exitNotFound     = 4
+	exitRateLimited  = 5
+	exitUnavailable  = 6
+	exitBadResponse  = 7
+	exitInterrupted  = 130
+)
+
+// apiFailure retorna el código de salida y un consejo para un error de la API
+func apiFailure(err error) (int, string) {
+	var rateErr *api.RateLimitError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted, "Interrumpido: las partidas ya guardadas quedan en el histórico"
+	case errors.Is(err, api.ErrUnauthorized):
+		return exitUnauthorized, "La API rechazó la key: revisá VALO_API_KEY (las keys de HenrikDev pueden vencer)"
+	case errors.Is(err, api.ErrNotFound):
+		return exitNotFound, "No existe en la API: revisá VALO_MAIN_PLAYER_NAME, VALO_MAIN_PLAYER_TAG y VALO_REGION (o si el jugador cambió su Riot ID)"
+	case errors.As(err, &rateErr):
+		if rateErr.RetryAfter > 0 {
+			return exitRateLimited, "La API sigue limitando los requests: reintentar en " + rateErr.RetryAfter.String() + " o bajar VALO_MAX_REQUESTS_PER_MINUTE"
+		}
+		return exitRateLimited, "La API sigue limitando los requests: esperar un minuto o bajar VALO_MAX_REQUESTS_PER_MINUTE"
+	case errors.Is(err, api.ErrDecode):
+		return exitBadResponse, "La API respondió algo inesperado: puede haber cambiado el formato, revisar VALO_API_BASE_URL"
+	case errors.Is(err, api.ErrUpstream), errors.Is(err, api.ErrRetriesExhausted):
+		return exitUnavailable, "La API no está disponible: reintentar más tarde (o subir VALO_MAX_RETRIES)"
+	default:
+		return exitError, ""
+	}
+}
+
+// apiExit muestra el error con un consejo según su clase y retorna su código de salida
+func apiExit(action string, err error) int {
+	code, hint := apiFailure(err)
+	log.Printf("Error %s: %v", action, err)
+	if hint != "" {
+		log.Printf("💡 %s", hint)
+	}
+	return code
+}
+
+// stopOnAPIError indica si un error al descargar una partida hace inútil seguir con las
+// demás (key inválida o ejecución cancelada). El resto se saltea y se sigue.
+func stopOnAPIError(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || errors.Is(err, api.ErrUnauthorized)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"valo-track/internal/api"
+)
+
+func TestAPIFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantHint string // Fragmento del consejo (vacío = sin consejo)
+	}{
+		{"key inválida", &api.StatusError{StatusCode: 401}, exitUnauthorized, "VALO_API_KEY"},
+		{"ErrUnauthorized envuelto", fmt.Errorf("cuenta: %w", api.ErrUnauthorized), exitUnauthorized, "VALO_API_KEY"},
+		{"no encontrado", &api.StatusError{StatusCode: 404}, exitNotFound, "VALO_MAIN_PLAYER_NAME"},
+		{"429 con Retry-After", &api.RateLimitError{RetryAfter: 30 * time.Second}, exitRateLimited, "reintentar en 30s"},
+		{"429 sin Retry-After", &api.RateLimitError{}, exitRateLimited, "esperar un minuto"},
+		// El rate limit manda sobre los reintentos agotados que lo envuelven
+		{"reintentos agotados por 429", &api.RetriesError{Attempts: 4, Err: &api.RateLimitError{RetryAfter: time.Minute}}, exitRateLimited, "reintentar en 1m0s"},
+		{"reintentos agotados por 5xx", &api.RetriesError{Attempts: 4, Err: &api.StatusError{StatusCode: 503}}, exitUnavailable, "no está disponible"},
+		{"5xx sin reintentos", &api.StatusError{StatusCode: 500}, exitUnavailable, "no está disponible"},
+		{"respuesta inválida", &api.DecodeError{What: "partida", Err: errors.New("eof")}, exitBadResponse, "formato"},
+		{"cancelado", fmt.Errorf("lista: %w", context.Canceled), exitInterrupted, "Interrumpido"},
+		{"cancelado durante un reintento", &api.RetriesError{Attempts: 2, Err: context.Canceled}, exitInterrupted, "Interrumpido"},
+		{"400 sin clase", &api.StatusError{StatusCode: 400}, exitError, ""},
+		{"error cualquiera", errors.New("disco lleno"), exitError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, hint := apiFailure(tt.err)
+			if code != tt.wantCode {
+				t.Errorf("código = %d, se esperaba %d", code, tt.wantCode)
+			}
+			if tt.wantHint == "" && hint != "" {
+				t.Errorf("consejo = %q, se esperaba ninguno", hint)
+			}
+			if !strings.Contains(hint, tt.wantHint) {
+				t.Errorf("consejo = %q, se esperaba que contenga %q", hint, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestStopOnAPIError(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"key inválida", context.Background(), &api.StatusError{StatusCode: 403}, true},
+		{"ejecución cancelada", cancelled, errors.New("cualquiera"), true},
+		{"partida inexistente", context.Background(), &api.StatusError{StatusCode: 404}, false},
+		{"reintentos agotados", context.Background(), &api.RetriesError{Attempts: 3, Err: &api.StatusError{StatusCode: 502}}, false},
+	}
+	for _, tt := range tests {
+		if got := stopOnAPIError(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: stopOnAPIError() = %v, se esperaba %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,179 +1,509 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"io/fs"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+	"valo-track/internal/accounts"
 	"valo-track/internal/analytics"
 	"valo-track/internal/api"
 	"valo-track/internal/config"
+	"valo-track/internal/heatmap"
 	"valo-track/internal/models"
-	"valo-track/internal/queue"
+	"valo-track/internal/storage"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run ejecuta los comandos pedidos y retorna el código de salida. Los errores se retornan
+// en lugar de llamar a os.Exit para que corran los defer (cerrar la base de datos y
+// guardar el registro de cuentas).
+func run() int {
 	// Parsear argumentos de línea de comandos
 	analyzeFlag := flag.Bool("analyze", false, "Realizar análisis de partidas")
 	updateFlag := flag.Bool("update", false, "Actualizar datos desde API")
+	syncFlag := flag.Bool("sync", false, "Sincronizar solo partidas nuevas y agregarlas al histórico")
+	importFlag := flag.Bool("import", false, "Importar el archivo JSON de partidas (VALO_MATCH_DATA_FILE) a la base de datos")
+	importDumpFlag := flag.Bool("import-dump", false, "Importar respuestas guardadas de la API (v2 o v4, una partida o una lista): -import-dump <archivo o directorio>...")
+	reprocessFlag := flag.Bool("reprocess", false, "Recalcular las partidas desde el archivo de respuestas crudas, sin usar la API")
+	leaderboardFlag := flag.Bool("leaderboard", false, "Rankear al stack por ACS, ADR, KAST, K/D, FK/FD, HS%, clutches y multi-kills")
+	sortFlag := flag.String("sort", "rating", "Métrica para ordenar el leaderboard (rating, acs, adr, kast, kd, fkfd, hs, clutches, multikills)")
+	compareFlag := flag.Bool("compare", false, "Comparar dos jugadores del stack: -compare <jugador A> <jugador B>")
+	timeframeFlag := flag.String("timeframe", "", "Ventana de análisis: all, daily, weekly, monthly, season o recent (por defecto VALO_TIMEFRAME)")
+	recentFlag := flag.Int("recent", 0, "Cantidad de partidas para -timeframe=recent (por defecto VALO_RECENT_MATCHES_TO_SHOW)")
+	seasonFlag := flag.String("season", "", "Season/acto para -timeframe=season, ej: e9a3 (por defecto el más reciente)")
+	fromFlag := flag.String("from", "", "Analizar desde esta fecha (AAAA-MM-DD)")
+	toFlag := flag.String("to", "", "Analizar hasta esta fecha inclusive (AAAA-MM-DD)")
+	economyFlag := flag.Bool("economy", false, "Analizar la economía: win rate por tipo de compra, loadout y daño por crédito gastado")
+	breakdownFlag := flag.Bool("breakdown", false, "Stats de un jugador por mapa, agente y mapa×agente: -breakdown [jugador]")
+	spikeFlag := flag.Bool("spike", false, "Analizar plants, defuses, post-plant y retakes por mapa")
+	clutchesFlag := flag.Bool("clutches", false, "Analizar clutches del stack por situación (1v1 a 1v5), ganados e intentados")
+	openingsFlag := flag.Bool("openings", false, "Analizar duelos de apertura (entry) por jugador, mapa y lado")
+	tradesFlag := flag.Bool("trades", false, "Analizar la red de trades del stack: quién tradea a quién")
+	synergyFlag := flag.Bool("synergy", false, "Win rate y rating por dupla, trío y lineup de 5 del stack")
+	minGamesFlag := flag.Int("min-games", 0, "Partidas mínimas juntos para -synergy (por defecto VALO_SYNERGY_MIN_GAMES)")
+	weaponsFlag := flag.Bool("weapons", false, "Stats por arma y clase de arma: -weapons [jugador] (sin nombre, todo el stack)")
+	heatmapFlag := flag.Bool("heatmap", false, "Generar heatmaps de kills y muertes por jugador, mapa y lado: -heatmap [jugador]")
+	mapFlag := flag.String("map", "", "Filtrar -heatmap por mapa")
+	sideFlag := flag.String("side", "", "Lado para -heatmap: all, attack o defense (por defecto los tres)")
+	formatFlag := flag.String("format", "svg", "Formato de -heatmap: svg, png o both")
+	accountsFlag := flag.Bool("accounts", false, "Mostrar las cuentas del stack por PUUID con su historial de nombres")
 	flag.Parse()
 
 	// Cargar configuración desde variables de entorno
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("Error cargando configuración: %v", err)
+		log.Printf("Error cargando configuración: %v", err)
+		return exitError
+	}
+	if cfg.PlayersFile == "" {
+		fmt.Printf("👥 Roster por defecto (%d jugadores). Crear %s/players.json para personalizarlo\n", len(cfg.Players), cfg.ConfigDir)
+	} else {
+		fmt.Printf("👥 Roster cargado desde %s (%d jugadores)\n", cfg.PlayersFile, len(cfg.Players))
+	}
+
+	// Ventana de tiempo para el análisis (-recent solo implica -timeframe=recent)
+	timeframeOpts := analytics.TimeframeOptions{
+		Kind:    *timeframeFlag,
+		Default: cfg.TimeframeAnalysis,
+		Recent:  *recentFlag,
+		Season:  *seasonFlag,
+		From:    *fromFlag,
+		To:      *toFlag,
+	}
+	if timeframeOpts.Kind == "" && *recentFlag > 0 {
+		timeframeOpts.Kind = analytics.TimeframeRecent
+	}
+	if timeframeOpts.Recent == 0 {
+		timeframeOpts.Recent = cfg.RecentMatchesToShow
+	}
+	timeframe, err := analytics.ParseTimeframe(timeframeOpts, time.Now())
+	if err != nil {
+		log.Printf("Error en ventana de tiempo: %v", err)
+		return exitError
+	}
+
+	// Crear archivo de respuestas crudas
+	rawArchive, err := storage.NewRawArchive(cfg.RawArchiveDir)
+	if err != nil {
+		log.Printf("Error creando archivo de respuestas crudas: %v", err)
+		return exitError
 	}
 
 	// Crear cliente de API
 	apiClient := api.NewAPIClient(cfg.APIKey, cfg.APIRegion, cfg.RequestTimeout, cfg.MaxRetries)
+	apiClient.SetBaseURL(cfg.APIBaseURL)
+	apiClient.SetArchive(rawArchive)
+
+	transport, err := api.NewTransport(cfg.APIMode, cfg.FixturesDir)
+	if err != nil {
+		log.Printf("Error configurando transporte HTTP: %v", err)
+		return exitError
+	}
+	apiClient.SetTransport(transport)
+
+	// Rate limiter compartido por todas las requests HTTP (en replay no hay red que cuidar)
+	limiter := api.NewRateLimiter(cfg.MaxRequestsPerMinute)
+	if cfg.APIMode != api.ModeReplay {
+		apiClient.SetRateLimiter(limiter)
+	}
 
 	// Crear servicio de análisis
 	analyticsService := analytics.NewAnalyticsService(cfg.PlayerAccountsMap, cfg.TradeWindowMs)
 
-	// Crear almacenamiento
-	storage := NewFileStorage(cfg.MatchDataFile, cfg.StatsOutputFile)
+	ratingWeights, err := analytics.ParseRatingWeights(cfg.RatingWeights)
+	if err != nil {
+		log.Printf("Error en VALO_RATING_WEIGHTS: %v", err)
+		return exitError
+	}
+	analyticsService.SetRatingWeights(ratingWeights)
+
+	// Registro de cuentas: identifica a cada jugador por PUUID aunque cambie de nombre
+	registry, err := accounts.NewRegistry(cfg.AccountsFile, cfg.Players)
+	if err != nil {
+		log.Printf("Error cargando registro de cuentas: %v", err)
+		return exitError
+	}
+	analyticsService.SetAccountResolver(registry)
+
+	// Fuera de modo live las cuentas vienen de fixtures: no tocar el cache real
+	registry.SetReadOnly(cfg.APIMode != api.ModeLive)
 
-	// Crear cola de solicitudes con rate limiting
-	reqQueue := queue.NewRequestQueue(cfg.MaxRequestsPerMinute, cfg.BatchSize, 100)
+	// Guardar el registro de cuentas también si un comando falla: los PUUIDs ya
+	// resueltos no se vuelven a pedir
+	defer func() {
+		if err := registry.Save(); err != nil {
+			log.Printf("Advertencia: %v", err)
+		}
+	}()
 
-	// Definir el procesador que usa la cola
-	processor := func(req *models.AnalysisRequest) *models.AnalysisResult {
-		return ProcessAnalysisRequest(req, apiClient, analyticsService, cfg)
+	// Abrir base de datos de partidas
+	store, err := storage.Open(cfg.DatabaseFile)
+	if err != nil {
+		log.Printf("Error abriendo almacenamiento: %v", err)
+		return exitError
 	}
+	defer store.Close()
 
-	// Iniciar workers
-	numWorkers := 3
-	reqQueue.StartWorkers(numWorkers, processor)
+	// Ctrl-C (o SIGTERM) cancela los requests en curso y las esperas de backoff
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *importFlag {
+		fmt.Println("=== IMPORTACIÓN DE HISTÓRICO ===")
+		fmt.Printf("Importando partidas desde %s...\n", cfg.MatchDataFile)
+
+		imported, skipped, err := storage.ImportJSON(store, cfg.MatchDataFile)
+		if err != nil {
+			log.Printf("Error importando partidas: %v", err)
+			return exitError
+		}
+		fmt.Printf("✅ %d partidas importadas, %d ya existentes u omitidas\n", imported, skipped)
+	}
 
 	if *updateFlag {
 		fmt.Println("=== ACTUALIZACIÓN DE DATOS ===")
+		ResolveAccounts(ctx, registry, apiClient, cfg)
 		fmt.Printf("Consultando partidas de %s#%s...\n", cfg.MainPlayerName, cfg.MainPlayerTag)
 
-		err := UpdateMatchData(apiClient, analyticsService, cfg, storage)
+		report, err := UpdateMatchData(ctx, apiClient, analyticsService, cfg, store)
 		if err != nil {
-			log.Fatalf("Error actualizando datos: %v", err)
+			return apiExit("actualizando datos", err)
 		}
-		fmt.Println("✅ Datos actualizados exitosamente")
+		fmt.Printf("✅ Datos actualizados: %d guardadas, %d fallidas, %d descartadas\n",
+			report.New, report.Failed, report.Discarded)
+		fmt.Printf("   Partidas en el histórico: %d\n", report.Total)
 	}
 
-	if *analyzeFlag {
-		fmt.Println("=== ANÁLISIS DE PARTIDAS ===")
+	if *syncFlag {
+		fmt.Println("=== SINCRONIZACIÓN INCREMENTAL ===")
+		ResolveAccounts(ctx, registry, apiClient, cfg)
+		fmt.Printf("Consultando partidas de %s#%s...\n", cfg.MainPlayerName, cfg.MainPlayerTag)
+
+		report, err := SyncMatchData(ctx, apiClient, analyticsService, cfg, store)
+		if err != nil {
+			return apiExit("sincronizando datos", err)
+		}
+		fmt.Printf("✅ Sincronización completa: %d nuevas, %d ya guardadas, %d ya descartadas, %d fallidas, %d descartadas\n",
+			report.New, report.Skipped, report.Ignored, report.Failed, report.Discarded)
+		fmt.Printf("   Partidas en el histórico: %d\n", report.Total)
+	}
+
+	if *importDumpFlag {
+		fmt.Println("=== IMPORTACIÓN DE RESPUESTAS DE LA API ===")
+		if flag.NArg() == 0 {
+			log.Printf("Uso: -import-dump <archivo o directorio>...")
+			return exitError
+		}
+
+		report, err := ImportDumps(flag.Args(), rawArchive, analyticsService, cfg, store)
+		if err != nil {
+			log.Printf("Error importando respuestas: %v", err)
+			return exitError
+		}
+		fmt.Printf("✅ Importación completa: %d partidas guardadas, %d fallidas, %d descartadas\n",
+			report.New, report.Failed, report.Discarded)
+		fmt.Printf("   Partidas en el histórico: %d\n", report.Total)
+	}
+
+	if *reprocessFlag {
+		fmt.Println("=== REPROCESAMIENTO OFFLINE ===")
+		fmt.Printf("Recalculando partidas desde %s...\n", cfg.RawArchiveDir)
+
+		report, err := ReprocessArchive(rawArchive, analyticsService, cfg, store)
+		if err != nil {
+			log.Printf("Error reprocesando partidas: %v", err)
+			return exitError
+		}
+		fmt.Printf("✅ Reprocesamiento completo: %d actualizadas, %d fallidas, %d descartadas\n",
+			report.New, report.Failed, report.Discarded)
+		fmt.Printf("   Partidas en el histórico: %d\n", report.Total)
+	}
+
+	// Reportar renombres detectados
+	PrintRenames(registry.Renames())
 
-		// Cargar datos guardados
-		matches, err := storage.LoadMatches()
+	if *accountsFlag {
+		fmt.Println("=== CUENTAS DEL STACK ===")
+		PrintAccounts(registry.Accounts(), registry.UnknownAliases())
+	}
+
+	// Partidas de la ventana: se cargan una sola vez y las comparten todos los reportes
+	var matches []models.MatchData
+	if *analyzeFlag || *leaderboardFlag || *compareFlag || *economyFlag || *breakdownFlag || *spikeFlag ||
+		*clutchesFlag || *openingsFlag || *tradesFlag || *synergyFlag || *weaponsFlag || *heatmapFlag {
+		matches, err = LoadMatches(store, &timeframe)
 		if err != nil {
-			log.Fatalf("Error cargando datos de partidas: %v", err)
+			log.Printf("Error cargando datos de partidas: %v", err)
+			return exitError
 		}
+		PrintTimeframe(timeframe, len(matches))
+	}
+
+	if *analyzeFlag {
+		fmt.Println("=== ANÁLISIS DE PARTIDAS ===")
 
 		if len(matches) == 0 {
-			fmt.Println("⚠️  No hay datos de partidas. Ejecuta con -update primero.")
-			return
+			if timeframe.Kind == analytics.TimeframeAll {
+				fmt.Println("⚠️  No hay datos de partidas. Ejecuta con -update primero.")
+			} else {
+				fmt.Printf("⚠️  No hay partidas en la ventana: %s\n", timeframe.Describe())
+			}
+		} else {
+			// Consolidar estadísticas de cada persona del stack
+			stackStats := analyticsService.AnalyzeStack(matches)
+
+			// Rating de cada jugador en cada partida (MVP y peor rendimiento)
+			matchRatings := make(map[string][]analytics.PlayerRating, len(matches))
+			for _, match := range matches {
+				matchRatings[match.MatchID] = analyticsService.MatchRatings(match)
+			}
+
+			// Mostrar resultados: tabla comparativa, partidas y detalle del jugador principal
+			PrintStackTable(stackStats)
+			PrintMatchRatings(os.Stdout, matches, matchRatings, cfg.RecentMatchesToShow)
+			mainName := analyticsService.GetPlayerName(cfg.MainPlayerName, cfg.MainPlayerTag)
+			for _, stats := range stackStats {
+				if stats.Name == mainName {
+					PrintAnalysis(stats, matches)
+				}
+			}
+
+			// Guardar output
+			err = SaveStats(cfg.StatsOutputFile, timeframe.Describe(), stackStats, matches, matchRatings)
+			if err != nil {
+				log.Printf("Advertencia: No se pudieron guardar estadísticas: %v", err)
+			}
 		}
+	}
+
+	if *leaderboardFlag {
+		fmt.Println("=== LEADERBOARD DEL STACK ===")
 
-		// Procesar análisis a través de la cola
-		req := &models.AnalysisRequest{
-			PlayerName: cfg.MainPlayerName,
-			PlayerTag:  cfg.MainPlayerTag,
-			Region:     cfg.APIRegion,
-			QueueMode:  cfg.QueueMode,
-			MaxGames:   cfg.MaxGamesToAnalyze,
+		metric, err := analytics.MetricByKey(*sortFlag)
+		if err != nil {
+			log.Printf("Error en leaderboard: %v", err)
+			return exitError
 		}
 
-		resultChan := reqQueue.Enqueue(req)
-		result := <-resultChan
+		PrintLeaderboard(analyticsService.AnalyzeStack(matches), metric)
+	}
+
+	if *compareFlag {
+		fmt.Println("=== COMPARACIÓN ===")
 
-		if result.Error != nil {
-			log.Fatalf("Error en análisis: %v", result.Error)
+		if flag.NArg() != 2 {
+			log.Printf("Uso: -compare <jugador A> <jugador B>")
+			return exitError
 		}
 
-		// Mostrar resultados
-		PrintAnalysis(result.Stats, matches)
+		comparison, err := analyticsService.Compare(matches, flag.Arg(0), flag.Arg(1))
+		if err != nil {
+			log.Printf("Error en comparación: %v", err)
+			return exitError
+		}
 
-		// Guardar output
-		err = storage.SaveStats(result.Stats, matches)
+		PrintComparison(comparison)
+	}
+
+	if *economyFlag {
+		fmt.Println("=== ECONOMÍA ===")
+
+		PrintEconomy(analyticsService.AnalyzeEconomy(matches), analyticsService.AnalyzeStack(matches))
+	}
+
+	if *breakdownFlag {
+		fmt.Println("=== STATS POR MAPA Y AGENTE ===")
+
+		// Sin nombre se usa el jugador principal
+		name := cfg.MainPlayerName
+		if realName := analyticsService.GetPlayerName(cfg.MainPlayerName, cfg.MainPlayerTag); realName != "" {
+			name = realName
+		}
+		if flag.NArg() > 0 {
+			name = flag.Arg(0)
+		}
+
+		breakdown, err := analyticsService.Breakdown(matches, name)
 		if err != nil {
-			log.Printf("Advertencia: No se pudieron guardar estadísticas: %v", err)
+			log.Printf("Error en breakdown: %v", err)
+			return exitError
 		}
+
+		PrintBreakdown(breakdown)
 	}
 
-	// Obtener estado del rate limiter
-	status := reqQueue.GetStatus()
-	fmt.Printf("\n📊 Estado del Rate Limiter:\n")
-	fmt.Printf("   Requests en esta ventana: %d/%d\n", status.RequestsMade, cfg.MaxRequestsPerMinute)
-	fmt.Printf("   Solicitudes pendientes: %d\n", reqQueue.QueueSize())
+	if *spikeFlag {
+		fmt.Println("=== SPIKE ===")
 
-	// Detener la cola
-	reqQueue.Stop()
-}
+		PrintSpike(analyticsService.AnalyzeSpike(matches))
+	}
+
+	if *clutchesFlag {
+		fmt.Println("=== CLUTCHES ===")
 
-// ProcessAnalysisRequest procesa una solicitud de análisis
-func ProcessAnalysisRequest(req *models.AnalysisRequest, apiClient *api.APIClient, analyticsService *analytics.AnalyticsService, cfg *config.Config) *models.AnalysisResult {
-	result := &models.AnalysisResult{
-		PlayerName: req.PlayerName,
-		PlayerTag:  req.PlayerTag,
-		Timestamp:  0, // Será actualizado
+		PrintClutches(analyticsService.AnalyzeStack(matches))
 	}
 
-	// Obtener lista de partidas
-	matchIDs, err := apiClient.GetLifetimeMatches(req.PlayerName, req.PlayerTag, req.QueueMode)
-	if err != nil {
-		result.Error = fmt.Errorf("error obteniendo partidas: %w", err)
-		return result
+	if *openingsFlag {
+		fmt.Println("=== DUELOS DE APERTURA ===")
+
+		PrintOpenings(analyticsService.AnalyzeOpenings(matches))
 	}
 
-	if len(matchIDs) == 0 {
-		result.Error = fmt.Errorf("no se encontraron partidas para %s#%s", req.PlayerName, req.PlayerTag)
-		return result
+	if *tradesFlag {
+		fmt.Println("=== TRADES ===")
+
+		PrintTrades(analyticsService.AnalyzeTrades(matches))
 	}
 
-	// Limitar a MaxGames
-	if len(matchIDs) > req.MaxGames {
-		matchIDs = matchIDs[:req.MaxGames]
+	if *synergyFlag {
+		fmt.Println("=== SINERGIA ===")
+
+		minGames := cfg.SynergyMinGames
+		if *minGamesFlag > 0 {
+			minGames = *minGamesFlag
+		}
+
+		PrintSynergy(analyticsService.AnalyzeSynergy(matches, minGames))
 	}
 
-	fmt.Printf("Procesando %d partidas para %s#%s...\n", len(matchIDs), req.PlayerName, req.PlayerTag)
+	if *weaponsFlag {
+		fmt.Println("=== ARMAS ===")
+
+		stackStats := analyticsService.AnalyzeStack(matches)
+		if flag.NArg() > 0 {
+			name, err := analyticsService.ResolveStackName(flag.Arg(0))
+			if err != nil {
+				log.Printf("Error en armas: %v", err)
+				return exitError
+			}
+			selected := make([]*models.PlayerStats, 0, 1)
+			for _, stats := range stackStats {
+				if stats.Name == name {
+					selected = append(selected, stats)
+				}
+			}
+			stackStats = selected
+		}
 
-	// Procesar cada partida
-	matches := make([]models.MatchData, 0, len(matchIDs))
-	stackPlayerNames := []string{req.PlayerName}
+		PrintWeapons(stackStats)
+	}
 
-	for i, matchID := range matchIDs {
-		if i%10 == 0 {
-			fmt.Printf("  Progreso: %d/%d\n", i, len(matchIDs))
+	if *heatmapFlag {
+		fmt.Println("=== HEATMAPS ===")
+
+		sides := []string{"all", analytics.SideAttack, analytics.SideDefense}
+		switch *sideFlag {
+		case "":
+		case "all", analytics.SideAttack, analytics.SideDefense:
+			sides = []string{*sideFlag}
+		default:
+			log.Printf("Lado inválido %q (opciones: all, attack, defense)", *sideFlag)
+			return exitError
+		}
+
+		formats := []string{*formatFlag}
+		switch *formatFlag {
+		case "svg", "png":
+		case "both":
+			formats = []string{"svg", "png"}
+		default:
+			log.Printf("Formato inválido %q (opciones: svg, png, both)", *formatFlag)
+			return exitError
 		}
 
-		// Obtener detalles de la partida
-		apiMatch, err := apiClient.GetMatchDetailsV4(matchID)
+		calibrations, err := heatmap.LoadCalibrations(cfg.MapsFile)
 		if err != nil {
-			fmt.Printf("  ⚠️  Error procesando partida %s: %v\n", matchID, err)
-			continue
+			log.Printf("Error cargando calibraciones: %v", err)
+			return exitError
 		}
 
-		// Procesar y analizar
-		match := analyticsService.ProcessMatchDetails(apiMatch, cfg.MinStackPlayers)
-		if match != nil {
-			matches = append(matches, *match)
+		player := ""
+		if flag.NArg() > 0 {
+			player, err = analyticsService.ResolveStackName(flag.Arg(0))
+			if err != nil {
+				log.Printf("Error en heatmap: %v", err)
+				return exitError
+			}
+		}
+
+		positions := make([]analytics.Position, 0)
+		for _, position := range analyticsService.Positions(matches) {
+			if player != "" && position.Player != player {
+				continue
+			}
+			if *mapFlag != "" && !strings.EqualFold(position.Map, *mapFlag) {
+				continue
+			}
+			positions = append(positions, position)
+		}
+		if len(positions) == 0 {
+			fmt.Println("⚠️  No hay posiciones guardadas para esos filtros. Ejecuta con -sync o -reprocess primero.")
+		}
+
+		written, missing, err := SaveHeatmaps(cfg.HeatmapDir, positions, calibrations, sides, formats)
+		if err != nil {
+			log.Printf("Error generando heatmaps: %v", err)
+			return exitError
+		}
+		for _, mapName := range missing {
+			fmt.Printf("⚠️  %s no tiene calibración en %s, se ignora\n", mapName, cfg.MapsFile)
 		}
+		fmt.Printf("✅ %d heatmaps guardados en %s\n", len(written), cfg.HeatmapDir)
 	}
 
-	// Consolidar estadísticas
-	stats := analyticsService.AnalyzeMatches(matches, stackPlayerNames)
-	stats.Name = req.PlayerName
+	// Obtener estado del rate limiter
+	status := limiter.Status()
+	fmt.Printf("\n📊 Estado del Rate Limiter:\n")
+	fmt.Printf("   Requests en esta ventana: %d/%d (quedan %d)\n", status.RequestsMade, cfg.MaxRequestsPerMinute, status.RequestsRemaining)
 
-	result.Stats = stats
-	result.Matches = matches
+	return 0
+}
 
-	return result
+// LoadMatches carga las partidas guardadas dentro de la ventana de tiempo
+func LoadMatches(store storage.Store, timeframe *analytics.Timeframe) ([]models.MatchData, error) {
+	matches, err := store.Matches(storage.Filter{From: timeframe.From, To: timeframe.To})
+	if err != nil {
+		return nil, err
+	}
+	return timeframe.Apply(matches), nil
 }
 
-// UpdateMatchData actualiza los datos de partidas desde la API
-func UpdateMatchData(apiClient *api.APIClient, analyticsService *analytics.AnalyticsService, cfg *config.Config, storage *FileStorage) error {
-	matchIDs, err := apiClient.GetLifetimeMatches(cfg.MainPlayerName, cfg.MainPlayerTag, cfg.QueueMode)
+// ResolveAccounts obtiene desde la API los PUUIDs de las cuentas del roster que todavía
+// no están en el registro. Los fallos no cortan la ejecución: suelen ser alias viejos.
+// Solo corre en modo live: un fixture asignaría el mismo PUUID de prueba a todo el roster.
+func ResolveAccounts(ctx context.Context, registry *accounts.Registry, apiClient *api.APIClient, cfg *config.Config) {
+	if cfg.APIMode != api.ModeLive {
+		return
+	}
+
+	resolved, failures := registry.Resolve(ctx, apiClient, cfg.Players)
+	if resolved > 0 {
+		fmt.Printf("🔑 %d cuentas nuevas identificadas por PUUID\n", resolved)
+	}
+	for _, err := range failures {
+		fmt.Printf("  ⚠️  No se pudo resolver el PUUID de %v\n", err)
+	}
+}
+
+// UpdateMatchData vuelve a descargar las últimas partidas desde la API y las guarda,
+// reemplazando las que ya estaban
+func UpdateMatchData(ctx context.Context, apiClient *api.APIClient, analyticsService *analytics.AnalyticsService, cfg *config.Config, store storage.Store) (*models.SyncReport, error) {
+	matchIDs, err := apiClient.GetLifetimeMatches(ctx, cfg.MainPlayerName, cfg.MainPlayerTag, cfg.QueueMode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(matchIDs) > cfg.MaxGamesToAnalyze {
@@ -182,163 +512,350 @@ func UpdateMatchData(apiClient *api.APIClient, analyticsService *analytics.Analy
 
 	fmt.Printf("Descargando %d partidas...\n", len(matchIDs))
 
-	matches := make([]models.MatchData, 0)
+	// Cada partida se guarda apenas se procesa: si se corta, lo descargado queda guardado
+	report := &models.SyncReport{}
+	saved, err := DownloadMatches(ctx, apiClient, analyticsService, cfg, matchIDs, report, store)
+	report.New = len(saved)
+	if err != nil {
+		return nil, fmt.Errorf("actualización interrumpida tras %d partidas guardadas: %w", report.New, err)
+	}
 
-	for i, matchID := range matchIDs {
-		if i%10 == 0 {
-			fmt.Printf("  Progreso: %d/%d\n", i, len(matchIDs))
-		}
+	report.Total, err = store.Count()
+	if err != nil {
+		return nil, err
+	}
 
-		apiMatch, err := apiClient.GetMatchDetailsV4(matchID)
+	return report, nil
+}
+
+// SyncMatchData descarga solo las partidas que no están en el histórico y las guarda
+// a medida que llegan, sin tocar las partidas existentes. Tampoco vuelve a descargar las
+// que el análisis descartó en una sincronización anterior (por ejemplo, partidas sin el
+// stack): -reprocess las reevalúa desde el archivo crudo.
+func SyncMatchData(ctx context.Context, apiClient *api.APIClient, analyticsService *analytics.AnalyticsService, cfg *config.Config, store storage.Store) (*models.SyncReport, error) {
+	matchIDs, err := apiClient.GetLifetimeMatches(ctx, cfg.MainPlayerName, cfg.MainPlayerTag, cfg.QueueMode)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SyncReport{}
+	pending := make([]string, 0, len(matchIDs))
+	for _, matchID := range matchIDs {
+		exists, err := store.HasMatch(matchID)
 		if err != nil {
-			fmt.Printf("  ⚠️  Error en partida %s: %v\n", matchID, err)
+			return nil, fmt.Errorf("error consultando histórico: %w", err)
+		}
+		if exists {
+			report.Skipped++
 			continue
 		}
-
-		match := analyticsService.ProcessMatchDetails(apiMatch, cfg.MinStackPlayers)
-		if match != nil {
-			matches = append(matches, *match)
+		discarded, err := store.IsDiscarded(matchID)
+		if err != nil {
+			return nil, fmt.Errorf("error consultando histórico: %w", err)
 		}
+		if discarded {
+			report.Ignored++
+			continue
+		}
+		pending = append(pending, matchID)
 	}
 
-	return storage.SaveMatches(matches)
-}
-
-// PrintAnalysis imprime un análisis bonito de los stats
-func PrintAnalysis(stats *models.PlayerStats, matches []models.MatchData) {
-	if stats == nil {
-		fmt.Println("❌ No hay estadísticas para mostrar")
-		return
+	// Las partidas recientes llegan completas en una sola request: no pedirlas de a una
+	if len(pending) > 0 && cfg.MatchListSize > 0 {
+		pending, err = SyncFromMatchList(ctx, apiClient, analyticsService, cfg, store, pending, report)
+		if err != nil {
+			return nil, fmt.Errorf("sincronización interrumpida tras %d partidas nuevas: %w", report.New, err)
+		}
 	}
 
-	fmt.Printf("\n=== ESTADÍSTICAS DE %s ===\n\n", stats.Name)
-	fmt.Printf("📊 RESUMEN GENERAL\n")
-	fmt.Printf("   Partidas jugadas: %d\n", stats.TotalGames)
-	fmt.Printf("   Victorias/Derrotas: %d/%d\n", stats.Wins, stats.Losses)
-	if stats.TotalGames > 0 {
-		winRate := float64(stats.Wins) * 100 / float64(stats.TotalGames)
-		fmt.Printf("   Win Rate: %.1f%%\n", winRate)
+	fmt.Printf("Descargando %d partidas nuevas (%d ya guardadas, %d ya descartadas)...\n", len(pending), report.Skipped, report.Ignored)
+
+	saved, err := DownloadMatches(ctx, apiClient, analyticsService, cfg, pending, report, store)
+	report.New += len(saved)
+	if err != nil {
+		// Las partidas ya guardadas quedan en el histórico
+		return nil, fmt.Errorf("sincronización interrumpida tras %d partidas nuevas: %w", report.New, err)
 	}
-	fmt.Printf("   Total de rondas: %d\n\n", stats.TotalRounds)
 
-	fmt.Printf("💀 COMBATE\n")
-	fmt.Printf("   Kills: %d\n", stats.Kills)
-	fmt.Printf("   Deaths: %d\n", stats.Deaths)
-	fmt.Printf("   Assists: %d\n", stats.Assists)
-	if stats.TotalGames > 0 {
-		fmt.Printf("   K/D Promedio: %.2f\n", float64(stats.Kills)/float64(stats.Deaths+1))
-		fmt.Printf("   Kills por partida: %.1f\n", float64(stats.Kills)/float64(stats.TotalGames))
+	report.Total, err = store.Count()
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("   Headshots/Bodyshots/Legshots: %d/%d/%d\n\n", stats.Headshots, stats.Bodyshots, stats.Legshots)
+	return report, nil
+}
 
-	fmt.Printf("🎯 ESTADÍSTICAS AVANZADAS\n")
-	fmt.Printf("   First Kills: %d\n", stats.FirstKills)
-	fmt.Printf("   First Deaths: %d\n", stats.FirstDeaths)
-	fmt.Printf("   KAST Rounds: %d\n", stats.KASTRounds)
-	fmt.Printf("   Clutches: %d\n", stats.Clutches)
+// SyncFromMatchList guarda las partidas pendientes que vienen en la lista de partidas
+// completas de v3/matches (formato v2) y retorna las que todavía hay que descargar de a
+// una. Si la lista falla se sigue sin ella, salvo que se haya cancelado o la key sea inválida.
+// Las partidas v2 traen solo el SeasonID: el nombre se toma del histórico y, si el acto es
+// nuevo, una de sus partidas queda para v4 (que sí lo trae) y las demás lo toman de ella.
+func SyncFromMatchList(ctx context.Context, apiClient *api.APIClient, analyticsService *analytics.AnalyticsService, cfg *config.Config, store storage.Store, pending []string, report *models.SyncReport) ([]string, error) {
+	list, err := apiClient.GetMatchList(ctx, cfg.MainPlayerName, cfg.MainPlayerTag, cfg.QueueMode, cfg.MatchListSize)
+	if err != nil {
+		if stopOnAPIError(ctx, err) {
+			return nil, err
+		}
+		fmt.Printf("  ⚠️  No se pudo usar la lista de partidas, se descargan de a una: %v\n", err)
+		return pending, nil
+	}
 
-	fmt.Printf("   Multi-Kills:\n")
-	for count := 2; count <= 5; count++ {
-		fmt.Printf("      %dK: %d\n", count, stats.MultiKills[count])
+	seasons, err := knownSeasons(store)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("\n⚔️  ATAQUE vs DEFENSA\n")
-	fmt.Printf("   Ataque  - Kills/Deaths/Damage: %d/%d/%d\n", stats.AttackKills, stats.AttackDeaths, stats.AttackDamage)
-	fmt.Printf("   Defensa - Kills/Deaths/Damage: %d/%d/%d\n", stats.DefenseKills, stats.DefenseDeaths, stats.DefenseDamage)
+	isPending := make(map[string]bool, len(pending))
+	for _, matchID := range pending {
+		isPending[matchID] = true
+	}
 
-	fmt.Printf("\n💰 ECONOMÍA\n")
-	fmt.Printf("   Score total: %d\n", stats.Score)
-	fmt.Printf("   Damage hecho/Recibido: %d/%d\n", stats.DamageMade, stats.DamageReceived)
+	for _, apiMatch := range list {
+		matchID := analyticsService.MatchID(apiMatch)
+		if !isPending[matchID] {
+			continue
+		}
 
-	fmt.Printf("\n🎮 AGENTES MÁS JUGADOS\n")
-	for agent, count := range stats.Agents {
-		fmt.Printf("   %s: %d veces\n", agent, count)
+		match := analyticsService.ProcessMatchDetails(apiMatch, cfg.MinStackPlayers)
+		if match == nil {
+			delete(isPending, matchID)
+			report.Discarded++
+			if err := store.MarkDiscarded(matchID); err != nil {
+				return nil, fmt.Errorf("error registrando partida descartada %s: %w", matchID, err)
+			}
+			continue
+		}
+		if match.Season == "" && match.SeasonID != "" {
+			name, known := seasons[match.SeasonID]
+			if !known {
+				// Acto sin nombre conocido: esta partida se descarga de v4
+				seasons[match.SeasonID] = ""
+				continue
+			}
+			match.Season = name
+		}
+		delete(isPending, matchID)
+		if err := store.SaveMatch(*match); err != nil {
+			return nil, fmt.Errorf("error guardando partida %s: %w", matchID, err)
+		}
+		report.New++
 	}
 
-	fmt.Printf("\n📈 ÚLTIMAS PARTIDAS ANALIZADAS: %d\n", len(matches))
+	remaining := make([]string, 0, len(isPending))
+	for _, matchID := range pending {
+		if isPending[matchID] {
+			remaining = append(remaining, matchID)
+		}
+	}
+	if fromList := len(pending) - len(remaining); fromList > 0 {
+		fmt.Printf("  %d partidas obtenidas de la lista de partidas recientes\n", fromList)
+	}
+	return remaining, nil
 }
 
-// FileStorage gestiona la persistencia de datos
-type FileStorage struct {
-	matchDataFile string
-	statsFile     string
-}
+// knownSeasons retorna el nombre de cada SeasonID que aparece en el histórico
+func knownSeasons(store storage.Store) (map[string]string, error) {
+	matches, err := store.Matches(storage.Filter{})
+	if err != nil {
+		return nil, fmt.Errorf("error consultando histórico: %w", err)
+	}
 
-// NewFileStorage crea un nuevo gestor de almacenamiento
-func NewFileStorage(matchDataFile, statsFile string) *FileStorage {
-	return &FileStorage{
-		matchDataFile: matchDataFile,
-		statsFile:     statsFile,
+	seasons := make(map[string]string)
+	for _, match := range matches {
+		if match.Season != "" && match.SeasonID != "" {
+			seasons[match.SeasonID] = match.Season
+		}
 	}
+	return seasons, nil
 }
 
-// LoadMatches carga los datos de partidas guardados
-func (fs *FileStorage) LoadMatches() ([]models.MatchData, error) {
-	data, err := ioutil.ReadFile(fs.matchDataFile)
-	if err != nil {
-		return nil, err
+// DownloadMatches descarga los detalles de las partidas con hasta cfg.BatchSize requests
+// en paralelo (el rate limiter del cliente mantiene el cupo) y las procesa a medida que
+// llegan. Cada partida se guarda en store apenas se procesa, así un corte a mitad de
+// camino no pierde lo ya descargado, y las descartadas quedan registradas para no volver
+// a pedirlas. Las fallidas y descartadas se cuentan en report. Retorna las partidas
+// procesadas ordenadas por fecha, también si hubo error.
+func DownloadMatches(ctx context.Context, apiClient *api.APIClient, analyticsService *analytics.AnalyticsService, cfg *config.Config, matchIDs []string, report *models.SyncReport, store storage.Store) ([]models.MatchData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Libera los workers si se corta antes de terminar
+
+	matches := make([]models.MatchData, 0, len(matchIDs))
+	var err error
+
+	done := 0
+	for fetched := range apiClient.FetchMatches(ctx, matchIDs, cfg.BatchSize) {
+		done++
+		if done%10 == 0 {
+			fmt.Printf("  Progreso: %d/%d\n", done, len(matchIDs))
+		}
+
+		if fetched.Err != nil {
+			if stopOnAPIError(ctx, fetched.Err) {
+				err = fetched.Err
+				break
+			}
+			fmt.Printf("  ⚠️  Error en partida %s: %v\n", fetched.MatchID, fetched.Err)
+			report.Failed++
+			continue
+		}
+
+		// El procesamiento es secuencial: solo las descargas van en paralelo
+		match := analyticsService.ProcessMatchDetails(fetched.Match, cfg.MinStackPlayers)
+		if match == nil {
+			report.Discarded++
+			if markErr := store.MarkDiscarded(fetched.MatchID); markErr != nil {
+				err = fmt.Errorf("error registrando partida descartada %s: %w", fetched.MatchID, markErr)
+				break
+			}
+			continue
+		}
+
+		if saveErr := store.SaveMatch(*match); saveErr != nil {
+			err = fmt.Errorf("error guardando partida %s: %w", fetched.MatchID, saveErr)
+			break
+		}
+		matches = append(matches, *match)
 	}
 
-	var matches []models.MatchData
-	if err := json.Unmarshal(data, &matches); err != nil {
-		return nil, err
+	if err == nil {
+		err = ctx.Err()
 	}
 
-	return matches, nil
+	// Las descargas llegan en orden de llegada: ordenar por fecha de la partida
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Timestamp < matches[j].Timestamp
+	})
+
+	return matches, err
 }
 
-// SaveMatches guarda los datos de partidas
-func (fs *FileStorage) SaveMatches(matches []models.MatchData) error {
-	data, err := json.MarshalIndent(matches, "", "  ")
+// ImportDumps importa respuestas de la API guardadas en archivos JSON: una partida (v2 o
+// v4) o una lista de partidas como la de v3/matches. Los directorios se recorren buscando
+// archivos .json. Cada partida se archiva cruda (para -reprocess) y se guarda en la base.
+func ImportDumps(paths []string, rawArchive *storage.RawArchive, analyticsService *analytics.AnalyticsService, cfg *config.Config, store storage.Store) (*models.SyncReport, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && (file == path || strings.HasSuffix(strings.ToLower(file), ".json")) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Printf("Importando %d archivos...\n", len(files))
+
+	report := &models.SyncReport{}
+	for _, file := range files {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		bodies, err := api.SplitMatches(body)
+		if err != nil {
+			fmt.Printf("  ⚠️  Error en %s: %v\n", file, err)
+			report.Failed++
+			continue
+		}
+
+		for _, matchBody := range bodies {
+			apiMatch, err := api.DecodeMatch(matchBody)
+			if err != nil {
+				fmt.Printf("  ⚠️  Error en %s: %v\n", file, err)
+				report.Failed++
+				continue
+			}
+
+			matchID := analyticsService.MatchID(apiMatch)
+			if matchID != "" {
+				if err := rawArchive.SaveRaw(matchID, matchBody); err != nil {
+					fmt.Printf("  ⚠️  No se pudo archivar la partida %s: %v\n", matchID, err)
+				}
+			}
+
+			match := analyticsService.ProcessMatchDetails(apiMatch, cfg.MinStackPlayers)
+			if match == nil {
+				report.Discarded++
+				if matchID != "" {
+					if err := store.MarkDiscarded(matchID); err != nil {
+						return nil, fmt.Errorf("error registrando partida descartada %s: %w", matchID, err)
+					}
+				}
+				continue
+			}
+
+			if err := store.SaveMatch(*match); err != nil {
+				return nil, fmt.Errorf("error guardando partida %s: %w", matchID, err)
+			}
+			report.New++
+		}
+	}
+
+	var err error
+	report.Total, err = store.Count()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return ioutil.WriteFile(fs.matchDataFile, data, 0644)
+	return report, nil
 }
 
-// SaveStats guarda el análisis de estadísticas
-func (fs *FileStorage) SaveStats(stats *models.PlayerStats, matches []models.MatchData) error {
-	f, err := os.Create(fs.statsFile)
+// ReprocessArchive vuelve a ejecutar el análisis sobre todas las respuestas crudas
+// archivadas y reemplaza las partidas guardadas, sin hacer requests a la API
+func ReprocessArchive(rawArchive *storage.RawArchive, analyticsService *analytics.AnalyticsService, cfg *config.Config, store storage.Store) (*models.SyncReport, error) {
+	matchIDs, err := rawArchive.List()
 	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	fmt.Fprintf(f, "[%s]\n", stats.Name)
-	fmt.Fprintf(f, "Partidas: %d | Victorias: %d | Derrotas: %d | WR: %.1f%%\n",
-		stats.TotalGames, stats.Wins, stats.Losses,
-		float64(stats.Wins)*100/float64(stats.TotalGames+1))
-	fmt.Fprintf(f, "K/D/A: %d/%d/%d | KDA: %.2f | +/-: %d\n",
-		stats.Kills, stats.Deaths, stats.Assists,
-		float64(stats.Kills+stats.Assists)/float64(stats.Deaths+1),
-		stats.Kills-stats.Deaths)
-
-	fmt.Fprintf(f, "Promedios: %.1f/%.1f/%.1f por partida\n",
-		float64(stats.Kills)/float64(stats.TotalGames+1),
-		float64(stats.Deaths)/float64(stats.TotalGames+1),
-		float64(stats.Assists)/float64(stats.TotalGames+1))
-
-	fmt.Fprintf(f, "ACS: %.2f | ADR: %.2f\n",
-		float64(stats.Score)/float64(stats.TotalGames+1),
-		float64(stats.DamageMade)/float64(stats.TotalRounds+1))
-
-	fmt.Fprintf(f, "FK/FD: %d/%d (%.1f%%)\n",
-		stats.FirstKills, stats.FirstDeaths,
-		float64(stats.FirstKills)*100/float64(stats.FirstKills+stats.FirstDeaths+1))
-
-	kastPct := float64(stats.KASTRounds) * 100 / float64(stats.TotalRounds+1)
-	if kastPct >= 60 {
-		fmt.Fprintf(f, "KAST: %.1f%% [OK] (%d rondas)\n", kastPct, stats.KASTRounds)
-	} else {
-		fmt.Fprintf(f, "KAST: %.1f%% [LOW] (%d rondas)\n", kastPct, stats.KASTRounds)
+		return nil, err
 	}
 
-	fmt.Fprintf(f, "Multi-Kills: 2K: %d | 3K: %d | 4K: %d | 5K: %d\n",
-		stats.MultiKills[2], stats.MultiKills[3], stats.MultiKills[4], stats.MultiKills[5])
+	fmt.Printf("Reprocesando %d partidas archivadas...\n", len(matchIDs))
+
+	report := &models.SyncReport{}
+	for i, matchID := range matchIDs {
+		if i%10 == 0 {
+			fmt.Printf("  Progreso: %d/%d\n", i, len(matchIDs))
+		}
+
+		body, err := rawArchive.LoadRaw(matchID)
+		if err != nil {
+			fmt.Printf("  ⚠️  Error leyendo partida %s: %v\n", matchID, err)
+			report.Failed++
+			continue
+		}
+
+		apiMatch, err := api.DecodeMatch(body)
+		if err != nil {
+			fmt.Printf("  ⚠️  Error en partida %s: %v\n", matchID, err)
+			report.Failed++
+			continue
+		}
+
+		match := analyticsService.ProcessMatchDetails(apiMatch, cfg.MinStackPlayers)
+		if match == nil {
+			report.Discarded++
+			if err := store.MarkDiscarded(matchID); err != nil {
+				return nil, fmt.Errorf("error registrando partida descartada %s: %w", matchID, err)
+			}
+			continue
+		}
+
+		if err := store.SaveMatch(*match); err != nil {
+			return nil, fmt.Errorf("error guardando partida %s: %w", matchID, err)
+		}
+		report.New++
+	}
 
-	fmt.Fprintf(f, "Clutches: %d\n", stats.Clutches)
+	report.Total, err = store.Count()
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return report, nil
 }
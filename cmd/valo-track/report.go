@@ -0,0 +1,700 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"valo-track/internal/accounts"
+	"valo-track/internal/analytics"
+	"valo-track/internal/heatmap"
+	"valo-track/internal/models"
+)
+
+// PrintAnalysis imprime un análisis bonito de los stats
+func PrintAnalysis(stats *models.PlayerStats, matches []models.MatchData) {
+	if stats == nil {
+		fmt.Println("❌ No hay estadísticas para mostrar")
+		return
+	}
+
+	fmt.Printf("\n=== ESTADÍSTICAS DE %s ===\n\n", stats.Name)
+	fmt.Printf("📊 RESUMEN GENERAL\n")
+	fmt.Printf("   Partidas jugadas: %d\n", stats.TotalGames)
+	fmt.Printf("   Victorias/Derrotas: %d/%d\n", stats.Wins, stats.Losses)
+	if stats.TotalGames > 0 {
+		winRate := float64(stats.Wins) * 100 / float64(stats.TotalGames)
+		fmt.Printf("   Win Rate: %.1f%%\n", winRate)
+	}
+	fmt.Printf("   Total de rondas: %d\n", stats.TotalRounds)
+	fmt.Printf("   Rating: %.2f\n\n", stats.Rating)
+
+	fmt.Printf("💀 COMBATE\n")
+	fmt.Printf("   Kills: %d\n", stats.Kills)
+	fmt.Printf("   Deaths: %d\n", stats.Deaths)
+	fmt.Printf("   Assists: %d\n", stats.Assists)
+	if stats.TotalGames > 0 {
+		fmt.Printf("   K/D Promedio: %.2f\n", float64(stats.Kills)/float64(stats.Deaths+1))
+		fmt.Printf("   Kills por partida: %.1f\n", float64(stats.Kills)/float64(stats.TotalGames))
+	}
+
+	fmt.Printf("   Headshots/Bodyshots/Legshots: %d/%d/%d\n\n", stats.Headshots, stats.Bodyshots, stats.Legshots)
+
+	fmt.Printf("🎯 ESTADÍSTICAS AVANZADAS\n")
+	fmt.Printf("   First Kills: %d\n", stats.FirstKills)
+	fmt.Printf("   First Deaths: %d\n", stats.FirstDeaths)
+	fmt.Printf("   KAST Rounds: %d\n", stats.KASTRounds)
+	clutches := analytics.ClutchTotal(stats)
+	fmt.Printf("   Clutches: %d/%d (%.1f%%)\n", clutches.Wins, clutches.Rounds, clutches.WinRate())
+	for _, record := range analytics.ClutchRecords(stats) {
+		if record.Rounds > 0 {
+			fmt.Printf("      %s: %d/%d (%.1f%%)\n", record.Name, record.Wins, record.Rounds, record.WinRate())
+		}
+	}
+
+	fmt.Printf("   Multi-Kills:\n")
+	for count := 2; count <= 5; count++ {
+		fmt.Printf("      %dK: %d\n", count, stats.MultiKills[count])
+	}
+
+	fmt.Printf("\n⚔️  ATAQUE vs DEFENSA\n")
+	fmt.Printf("   Ataque  - Kills/Deaths/Damage: %d/%d/%d\n", stats.AttackKills, stats.AttackDeaths, stats.AttackDamage)
+	fmt.Printf("   Defensa - Kills/Deaths/Damage: %d/%d/%d\n", stats.DefenseKills, stats.DefenseDeaths, stats.DefenseDamage)
+
+	fmt.Printf("\n💰 ECONOMÍA\n")
+	fmt.Printf("   Score total: %d\n", stats.Score)
+	fmt.Printf("   Damage hecho/Recibido: %d/%d\n", stats.DamageMade, stats.DamageReceived)
+
+	fmt.Printf("\n🎮 AGENTES MÁS JUGADOS\n")
+	for agent, count := range stats.Agents {
+		fmt.Printf("   %s: %d veces\n", agent, count)
+	}
+
+	fmt.Printf("\n📈 ÚLTIMAS PARTIDAS ANALIZADAS: %d\n", len(matches))
+}
+
+// PrintTimeframe imprime la ventana de tiempo activa
+func PrintTimeframe(timeframe analytics.Timeframe, matchCount int) {
+	fmt.Printf("🗓️  Ventana: %s (%d partidas)\n", timeframe.Describe(), matchCount)
+	if timeframe.Unseasoned > 0 {
+		fmt.Printf("⚠️  %d partidas sin season quedaron afuera (importadas sin datos de season o v2 de un acto sin nombre); usar -from/-to para incluirlas\n", timeframe.Unseasoned)
+	}
+}
+
+// PrintStackTable imprime las estadísticas de todo el stack lado a lado
+func PrintStackTable(stackStats []*models.PlayerStats) {
+	fmt.Printf("\n=== STACK (%d jugadores) ===\n\n", len(stackStats))
+	writeStackTable(os.Stdout, stackStats)
+}
+
+// writeStackTable escribe una fila por jugador con las métricas principales
+func writeStackTable(w io.Writer, stackStats []*models.PlayerStats) {
+	fmt.Fprintf(w, "%-12s %4s %6s %6s %5s %6s %6s %6s %6s %6s %4s %4s\n",
+		"Jugador", "PJ", "WR%", "Rating", "K/D", "ACS", "ADR", "KAST%", "FK/FD", "HS%", "CL", "MK")
+	for _, stats := range stackStats {
+		fmt.Fprintf(w, "%-12s %4d %6.1f %6.2f %5.2f %6.1f %6.1f %6.1f %6.2f %6.1f %4d %4d\n",
+			stats.Name, stats.TotalGames,
+			analytics.WinRate(stats), stats.Rating, analytics.KD(stats),
+			analytics.ACS(stats), analytics.ADR(stats), analytics.KASTPct(stats),
+			analytics.FKFDRatio(stats), analytics.HSPct(stats),
+			stats.Clutches, analytics.MultiKillTotal(stats))
+	}
+}
+
+// PrintMatchRatings escribe las últimas partidas con el MVP y el peor rendimiento del
+// stack en cada una (limit <= 0 = todas)
+func PrintMatchRatings(w io.Writer, matches []models.MatchData, matchRatings map[string][]analytics.PlayerRating, limit int) {
+	start := 0
+	if limit > 0 && len(matches) > limit {
+		start = len(matches) - limit
+	}
+
+	fmt.Fprintf(w, "\n🏅 PARTIDAS (MVP / peor rendimiento)\n")
+	for i := len(matches) - 1; i >= start; i-- {
+		match := matches[i]
+		result := "D"
+		if match.Won {
+			result = "V"
+		}
+
+		line := fmt.Sprintf("   %s %-8s %s", time.Unix(match.Timestamp, 0).Format("2006-01-02"), match.Map, result)
+		ratings := matchRatings[match.MatchID]
+		if len(ratings) > 0 {
+			mvp := ratings[0]
+			line += fmt.Sprintf("  MVP: %s (%.2f)", mvp.Name, mvp.Rating)
+		}
+		if len(ratings) > 1 {
+			worst := ratings[len(ratings)-1]
+			line += fmt.Sprintf("  Peor: %s (%.2f)", worst.Name, worst.Rating)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// PrintLeaderboard imprime el stack ordenado por una métrica y el líder de cada métrica
+func PrintLeaderboard(stackStats []*models.PlayerStats, sortMetric analytics.Metric) {
+	if len(stackStats) == 0 {
+		fmt.Println("⚠️  No hay datos de partidas. Ejecuta con -sync primero.")
+		return
+	}
+
+	fmt.Printf("\nOrdenado por %s\n\n", sortMetric.Label)
+	fmt.Printf("%3s %-12s %4s", "#", "Jugador", "PJ")
+	for _, metric := range analytics.LeaderboardMetrics {
+		fmt.Printf(" %11s", metric.Label)
+	}
+	fmt.Println()
+
+	for i, stats := range analytics.Leaderboard(stackStats, sortMetric) {
+		fmt.Printf("%3d %-12s %4d", i+1, stats.Name, stats.TotalGames)
+		for _, metric := range analytics.LeaderboardMetrics {
+			fmt.Printf(" %11s", fmt.Sprintf(metric.Format, metric.Value(stats)))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("\n🏆 LÍDERES\n")
+	for _, metric := range analytics.LeaderboardMetrics {
+		leader := analytics.Leaderboard(stackStats, metric)[0]
+		fmt.Printf("   %-12s %-12s "+metric.Format+"\n", metric.Label, leader.Name, metric.Value(leader))
+	}
+}
+
+// PrintComparison imprime dos jugadores lado a lado con sus diferencias
+func PrintComparison(comparison *analytics.Comparison) {
+	a, b := comparison.A, comparison.B
+
+	fmt.Printf("\n%-12s %12s %12s %10s\n", "", a.Name, b.Name, "Δ")
+	fmt.Printf("%-12s %12d %12d %10d\n", "Partidas", a.TotalGames, b.TotalGames, a.TotalGames-b.TotalGames)
+	printComparisonRow("WR%", "%.1f", analytics.WinRate(a), analytics.WinRate(b))
+	for _, metric := range analytics.LeaderboardMetrics {
+		printComparisonRow(metric.Label, metric.Format, metric.Value(a), metric.Value(b))
+	}
+
+	fmt.Printf("\n🗺️  POR MAPA (ACS / WR%%)\n")
+	printSplits(comparison.Maps, a.Name, b.Name)
+
+	fmt.Printf("\n🎮 POR AGENTE (ACS / WR%%)\n")
+	printSplits(comparison.Agents, a.Name, b.Name)
+}
+
+// printComparisonRow imprime una métrica de ambos jugadores y su diferencia (A - B)
+func printComparisonRow(label, format string, valueA, valueB float64) {
+	fmt.Printf("%-12s %12s %12s %10s\n", label,
+		fmt.Sprintf(format, valueA), fmt.Sprintf(format, valueB), fmt.Sprintf("%+"+format[1:], valueA-valueB))
+}
+
+// printSplits imprime los cortes compartidos indicando quién rinde mejor en cada uno
+func printSplits(splits []analytics.SplitComparison, nameA, nameB string) {
+	if len(splits) == 0 {
+		fmt.Println("   (sin datos en común)")
+		return
+	}
+
+	for _, split := range splits {
+		better := split.Better()
+		if better == "" {
+			better = "empate"
+		}
+		fmt.Printf("   %-12s %s: %6.1f / %5.1f%% (%d)  %s: %6.1f / %5.1f%% (%d)  → %s\n",
+			split.Name,
+			nameA, analytics.ACS(split.A), analytics.WinRate(split.A), split.A.TotalGames,
+			nameB, analytics.ACS(split.B), analytics.WinRate(split.B), split.B.TotalGames,
+			better)
+	}
+}
+
+// PrintEconomy imprime el rendimiento por tipo de compra y la eficiencia de cada jugador
+func PrintEconomy(report *analytics.EconomyReport, stackStats []*models.PlayerStats) {
+	if report.Rounds == 0 {
+		fmt.Println("⚠️  No hay rondas con datos de economía. Ejecuta con -sync o -reprocess primero.")
+		return
+	}
+
+	fmt.Printf("\n💰 WIN RATE POR COMPRA DEL STACK (%d rondas)\n", report.Rounds)
+	for _, buy := range report.ByBuy {
+		fmt.Printf("   %-10s %4d rondas  %5.1f%%\n", buy.Name, buy.Rounds, buy.WinRate())
+	}
+
+	fmt.Printf("\n📉 CON MENOS EQUIPAMIENTO QUE EL RIVAL (%d+ créditos)\n", analytics.OutBoughtMargin)
+	fmt.Printf("   %d rondas, %d ganadas (%.1f%%)\n", report.OutBought.Rounds, report.OutBought.Wins, report.OutBought.WinRate())
+	fmt.Printf("   Diferencia de loadout promedio: %+.0f créditos por ronda\n", report.AverageLoadoutDiff())
+
+	fmt.Printf("\n🎯 EFICIENCIA (daño cada 1000 créditos gastados)\n")
+	ranked := analytics.Leaderboard(stackStats, analytics.Metric{Value: analytics.DamagePer1000})
+	for _, stats := range ranked {
+		if stats.CreditsSpent == 0 {
+			continue
+		}
+		fmt.Printf("   %-12s %6.1f  (%d de daño con %dk gastados)\n",
+			stats.Name, analytics.DamagePer1000(stats), stats.EconomyDamage, stats.CreditsSpent/1000)
+	}
+}
+
+// PrintBreakdown imprime los stats de un jugador por mapa, por agente y por mapa×agente
+func PrintBreakdown(breakdown *analytics.Breakdown) {
+	if breakdown.Player.TotalGames == 0 {
+		fmt.Printf("⚠️  %s no tiene partidas en la ventana\n", breakdown.Player.Name)
+		return
+	}
+
+	fmt.Printf("\n=== %s (%d partidas) ===\n", breakdown.Player.Name, breakdown.Player.TotalGames)
+
+	fmt.Printf("\n🗺️  POR MAPA\n")
+	writeSplitTable(os.Stdout, breakdown.ByMap)
+	fmt.Printf("\n🎮 POR AGENTE\n")
+	writeSplitTable(os.Stdout, breakdown.ByAgent)
+	fmt.Printf("\n🧩 POR MAPA Y AGENTE\n")
+	writeSplitTable(os.Stdout, breakdown.ByMapAgent)
+}
+
+// writeSplitTable escribe una fila por corte con las métricas principales y el split por lado
+func writeSplitTable(w io.Writer, splits []analytics.SplitStats) {
+	fmt.Fprintf(w, "   %-22s %4s %6s %6s %6s %6s %6s %7s %7s %7s %7s\n",
+		"", "PJ", "WR%", "ACS", "ADR", "KAST%", "FK/FD", "ATK K/D", "DEF K/D", "ATK ADR", "DEF ADR")
+	for _, split := range splits {
+		stats := split.Stats
+		fmt.Fprintf(w, "   %-22s %4d %6.1f %6.1f %6.1f %6.1f %6.2f %7.2f %7.2f %7.1f %7.1f\n",
+			split.Label(), stats.TotalGames,
+			analytics.WinRate(stats), analytics.ACS(stats), analytics.ADR(stats),
+			analytics.KASTPct(stats), analytics.FKFDRatio(stats),
+			analytics.AttackKD(stats), analytics.DefenseKD(stats),
+			analytics.AttackADR(stats), analytics.DefenseADR(stats))
+	}
+}
+
+// PrintSpike imprime los stats de spike por mapa y quién planta y defusea más
+func PrintSpike(report *analytics.SpikeReport) {
+	if report.Overall.AttackRounds+report.Overall.DefenseRounds == 0 {
+		fmt.Println("⚠️  No hay rondas con lado conocido. Ejecuta con -sync o -reprocess primero.")
+		return
+	}
+
+	fmt.Printf("\n%-10s %7s %6s %9s %8s %11s  %s\n", "Mapa", "Plant%", "T.Plt", "PostPlt%", "Retake%", "Defuses", "Win% por sitio")
+	for _, stats := range append(report.Maps, report.Overall) {
+		sites := ""
+		for _, site := range stats.SiteRecords() {
+			sites += fmt.Sprintf("%s %.0f%% (%d)  ", site.Name, site.WinRate(), site.Rounds)
+		}
+		fmt.Printf("%-10s %6.1f%% %5.1fs %8.1f%% %7.1f%% %4d/%-6d  %s\n",
+			stats.Map, stats.PlantRate(), stats.AveragePlantTime(),
+			stats.PostPlant.WinRate(), stats.Retakes.WinRate(),
+			stats.Defuses, stats.Retakes.Rounds, sites)
+	}
+
+	fmt.Printf("\n💣 PLANTS\n")
+	printNamedCounts(report.Planters)
+	fmt.Printf("\n🔧 DEFUSES\n")
+	printNamedCounts(report.Defusers)
+}
+
+// PrintOpenings imprime los duelos de apertura por jugador, mapa y lado, el win rate de
+// ronda según quién hizo la primera kill y la distribución del primer contacto
+func PrintOpenings(report *analytics.OpeningReport) {
+	if len(report.Players) == 0 && report.AfterFirstKill.Rounds+report.AfterFirstDeath.Rounds == 0 {
+		fmt.Println("⚠️  No hay kills por ronda guardadas. Ejecuta con -sync o -reprocess primero.")
+		return
+	}
+
+	fmt.Printf("\n%-24s %6s %6s %11s %11s %8s %7s\n", "Jugador", "Duelos", "Win%", "Ataque", "Defensa", "FD trad", "T.prom")
+	for _, player := range report.Players {
+		writeOpeningRow(os.Stdout, player.Name, &player.OpeningStats)
+	}
+
+	fmt.Printf("\n🗺️  POR MAPA\n")
+	for _, player := range report.Players {
+		for _, mapStats := range player.Maps {
+			writeOpeningRow(os.Stdout, "  "+player.Name+" / "+mapStats.Name, mapStats)
+		}
+	}
+
+	fmt.Printf("\n📈 WIN RATE DE RONDA\n")
+	for _, record := range []analytics.RoundRecord{report.AfterFirstKill, report.AfterFirstDeath} {
+		fmt.Printf("   Con %-12s %5.1f%% (%d/%d)\n", record.Name+":", record.WinRate(), record.Wins, record.Rounds)
+	}
+
+	total := 0
+	for _, bucket := range report.FirstContact {
+		total += bucket.Count
+	}
+	fmt.Printf("\n⏱️  PRIMER CONTACTO (primera kill de la ronda)\n")
+	for _, bucket := range report.FirstContact {
+		pct := 0.0
+		if total > 0 {
+			pct = float64(bucket.Count) * 100 / float64(total)
+		}
+		fmt.Printf("   %-7s %4d  %5.1f%%  %s\n", bucket.Name, bucket.Count, pct, strings.Repeat("█", int(pct/2)))
+	}
+}
+
+// writeOpeningRow escribe una fila de duelos de apertura
+func writeOpeningRow(w io.Writer, label string, stats *analytics.OpeningStats) {
+	fmt.Fprintf(w, "%-24s %6d %5.1f%% %11s %11s %7.1f%% %6.1fs\n",
+		label, stats.Duels.Rounds, stats.Duels.WinRate(),
+		formatRecord(stats.Attack), formatRecord(stats.Defense),
+		stats.TradedPct(), stats.AverageTime())
+}
+
+// formatRecord formatea un registro como "WR% (rondas)"
+func formatRecord(record analytics.RoundRecord) string {
+	if record.Rounds == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%% (%d)", record.WinRate(), record.Rounds)
+}
+
+// PrintTrades imprime la matriz de trades del stack (filas: quién murió, columnas: quién
+// lo tradeó) y los trades de cada jugador
+func PrintTrades(report *analytics.TradeReport) {
+	if len(report.Players) == 0 {
+		fmt.Println("⚠️  No hay kills por ronda guardadas. Ejecuta con -sync o -reprocess primero.")
+		return
+	}
+
+	fmt.Printf("\n🔁 MATRIZ DE TRADES (fila murió, columna tradeó)\n")
+	fmt.Printf("%-12s", "")
+	for _, trader := range report.Players {
+		fmt.Printf(" %10s", trader.Name)
+	}
+	fmt.Println()
+	for _, victim := range report.Players {
+		fmt.Printf("%-12s", victim.Name)
+		for _, trader := range report.Players {
+			if victim.Name == trader.Name {
+				fmt.Printf(" %10s", "-")
+				continue
+			}
+			fmt.Printf(" %10d", report.Count(victim.Name, trader.Name))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("\n%-12s %7s %9s %10s %8s %9s\n", "Jugador", "Muertes", "Tradeado", "Sin trade", "T.kills", "T.reacc")
+	for _, stats := range report.Players {
+		fmt.Printf("%-12s %7d %9d %9.1f%% %8d %8.2fs\n",
+			stats.Name, stats.Deaths, stats.TradedDeaths, stats.UntradedPct(),
+			stats.TradeKills, stats.AverageTradeTime())
+	}
+}
+
+// PrintSynergy imprime win rate y rating promedio de duplas, tríos y lineups completos,
+// y el mejor y peor compañero de cada jugador
+func PrintSynergy(report *analytics.SynergyReport) {
+	fmt.Printf("   (mínimo %d partidas juntos)\n", report.MinGames)
+
+	for _, group := range []struct {
+		title   string
+		lineups []*analytics.Lineup
+	}{
+		{"👥 DUPLAS", report.Duos},
+		{"👥 TRÍOS", report.Trios},
+		{"👥 LINEUPS DE 5", report.Fives},
+	} {
+		fmt.Printf("\n%s\n", group.title)
+		if len(group.lineups) == 0 {
+			fmt.Println("   (sin datos suficientes)")
+			continue
+		}
+		fmt.Printf("   %-48s %4s %6s %6s\n", "Jugadores", "PJ", "WR%", "Rating")
+		for _, lineup := range group.lineups {
+			fmt.Printf("   %-48s %4d %6.1f %6.2f\n", lineup.Label(), lineup.Games, lineup.WinRate(), lineup.AverageRating())
+		}
+	}
+
+	fmt.Printf("\n🤝 MEJOR Y PEOR COMPAÑERO\n")
+	if len(report.Partners) == 0 {
+		fmt.Println("   (sin datos suficientes)")
+	}
+	for _, partners := range report.Partners {
+		fmt.Printf("   %-12s mejor: %-12s %5.1f%% (%d)   peor: %-12s %5.1f%% (%d)\n", partners.Player,
+			partners.Best.Partner(partners.Player), partners.Best.WinRate(), partners.Best.Games,
+			partners.Worst.Partner(partners.Player), partners.Worst.WinRate(), partners.Worst.Games)
+	}
+}
+
+// PrintWeapons imprime los stats por clase y arma de cada jugador
+func PrintWeapons(stackStats []*models.PlayerStats) {
+	if len(stackStats) == 0 {
+		fmt.Println("⚠️  No hay partidas del jugador en la ventana seleccionada.")
+		return
+	}
+
+	for _, stats := range stackStats {
+		breakdown := analytics.WeaponBreakdown(stats)
+		fmt.Printf("\n🔫 %s\n", stats.Name)
+		if len(breakdown) == 0 {
+			fmt.Println("   (sin datos de armas)")
+			continue
+		}
+
+		fmt.Printf("   %-14s %6s %6s %6s %8s %6s\n", "Arma", "Kills", "Rondas", "KPR", "Daño/R", "HS%")
+		for _, class := range breakdown {
+			writeWeaponLine(os.Stdout, strings.ToUpper(class.Total.Name), class.Total)
+			for _, weapon := range class.Weapons {
+				writeWeaponLine(os.Stdout, "  "+weapon.Name, weapon)
+			}
+		}
+	}
+}
+
+// writeWeaponLine escribe una fila de stats de arma. Las armas sin rondas compradas
+// (habilidades, armas levantadas del piso) solo muestran kills.
+func writeWeaponLine(w io.Writer, label string, line analytics.WeaponLine) {
+	if line.Rounds == 0 {
+		fmt.Fprintf(w, "   %-14s %6d %6s %6s %8s %6s\n", label, line.Kills, "-", "-", "-", "-")
+		return
+	}
+	fmt.Fprintf(w, "   %-14s %6d %6d %6.2f %8.1f %5.1f%%\n",
+		label, line.Kills, line.Rounds, line.KillsPerRound(), line.DamagePerRound(), line.HSPct())
+}
+
+// Lados de los heatmaps: todas las rondas, solo ataque o solo defensa
+const heatmapAllSides = "all"
+
+var heatmapSideLabels = map[string]string{
+	heatmapAllSides:       "todas las rondas",
+	analytics.SideAttack:  "ataque",
+	analytics.SideDefense: "defensa",
+}
+
+// Tamaño en pixeles de cada formato de heatmap
+var heatmapSizes = map[string]int{"svg": 1024, "png": 512}
+
+// SaveHeatmaps escribe un heatmap de kills y muertes por jugador, mapa y lado en
+// dir/<jugador>/<mapa>_<lado>.<formato>. Retorna los archivos escritos y los mapas que
+// no tienen calibración (sus posiciones se ignoran).
+func SaveHeatmaps(dir string, positions []analytics.Position, calibrations heatmap.Calibrations, sides, formats []string) ([]string, []string, error) {
+	type groupKey struct{ player, mapName string }
+	groups := make(map[groupKey][]analytics.Position)
+	missing := make(map[string]bool)
+	for _, position := range positions {
+		if _, ok := calibrations.Lookup(position.Map); !ok {
+			missing[position.Map] = true
+			continue
+		}
+		key := groupKey{position.Player, position.Map}
+		groups[key] = append(groups[key], position)
+	}
+
+	written := make([]string, 0)
+	for key, group := range groups {
+		calibration, _ := calibrations.Lookup(key.mapName)
+
+		for _, side := range sides {
+			hm := heatmap.Heatmap{Title: fmt.Sprintf("%s - %s (%s)", key.player, key.mapName, heatmapSideLabels[side])}
+			for _, position := range group {
+				if side != heatmapAllSides && position.Side != side {
+					continue
+				}
+				u, v := calibration.Normalize(position.X, position.Y)
+				if u < 0 || u > 1 || v < 0 || v > 1 {
+					continue // Fuera del minimapa: calibración o dato inválido
+				}
+				hm.Points = append(hm.Points, heatmap.Point{U: u, V: v, Kill: position.Kill})
+			}
+			if len(hm.Points) == 0 {
+				continue
+			}
+
+			playerDir := filepath.Join(dir, fileSafeName(key.player))
+			if err := os.MkdirAll(playerDir, 0755); err != nil {
+				return written, nil, fmt.Errorf("error creando %s: %w", playerDir, err)
+			}
+
+			for _, format := range formats {
+				path := filepath.Join(playerDir, fmt.Sprintf("%s_%s.%s", fileSafeName(key.mapName), side, format))
+				if err := writeHeatmapFile(path, format, hm); err != nil {
+					return written, nil, err
+				}
+				written = append(written, path)
+			}
+		}
+	}
+	sort.Strings(written)
+
+	missingMaps := make([]string, 0, len(missing))
+	for mapName := range missing {
+		missingMaps = append(missingMaps, mapName)
+	}
+	sort.Strings(missingMaps)
+
+	return written, missingMaps, nil
+}
+
+// writeHeatmapFile escribe un heatmap en el formato indicado (svg o png)
+func writeHeatmapFile(path, format string, hm heatmap.Heatmap) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creando %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if format == "png" {
+		err = heatmap.WritePNG(f, hm, heatmapSizes[format])
+	} else {
+		err = heatmap.WriteSVG(f, hm, heatmapSizes[format])
+	}
+	if err != nil {
+		return fmt.Errorf("error escribiendo %s: %w", path, err)
+	}
+	return nil
+}
+
+// fileSafeName reemplaza por _ los caracteres que no son letras ni números
+func fileSafeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// PrintClutches imprime por jugador los clutches ganados/intentados en cada situación 1vN
+func PrintClutches(stackStats []*models.PlayerStats) {
+	fmt.Printf("\n%-12s", "Jugador")
+	for enemies := 1; enemies <= analytics.MaxClutchEnemies; enemies++ {
+		fmt.Printf(" %13s", fmt.Sprintf("1v%d", enemies))
+	}
+	fmt.Printf(" %15s\n", "Total")
+
+	for _, stats := range stackStats {
+		fmt.Printf("%-12s", stats.Name)
+		for _, record := range analytics.ClutchRecords(stats) {
+			fmt.Printf(" %13s", formatClutch(record))
+		}
+		fmt.Printf(" %15s\n", formatClutch(analytics.ClutchTotal(stats)))
+	}
+}
+
+// formatClutch formatea un registro de clutch como "ganados/intentos (WR%)"
+func formatClutch(record analytics.RoundRecord) string {
+	if record.Rounds == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d (%.0f%%)", record.Wins, record.Rounds, record.WinRate())
+}
+
+// printNamedCounts imprime un conteo por jugador
+func printNamedCounts(counts []analytics.NamedCount) {
+	if len(counts) == 0 {
+		fmt.Println("   (sin datos)")
+		return
+	}
+	for _, count := range counts {
+		fmt.Printf("   %-12s %d\n", count.Name, count.Count)
+	}
+}
+
+// PrintRenames avisa de las cuentas del stack que cambiaron de name#tag
+func PrintRenames(renames []accounts.Rename) {
+	if len(renames) == 0 {
+		return
+	}
+
+	fmt.Printf("\n🏷️  RENOMBRES DETECTADOS\n")
+	for _, rename := range renames {
+		fmt.Printf("   %-12s %s → %s\n", rename.Player, rename.From, rename.To)
+	}
+	fmt.Println("   Las partidas siguen contando para la misma persona (se identifican por PUUID).")
+}
+
+// PrintAccounts imprime cada cuenta conocida con los nombres que usó
+func PrintAccounts(known []accounts.Account, unknownAliases map[string][]string) {
+	if len(known) == 0 {
+		fmt.Println("⚠️  No hay cuentas identificadas por PUUID. Ejecuta con -sync primero.")
+		return
+	}
+
+	for _, account := range known {
+		fmt.Printf("\n%-12s %s\n", account.Player, account.RiotID)
+		fmt.Printf("   PUUID: %s\n", account.PUUID)
+		for _, alias := range account.Aliases {
+			fmt.Printf("   - %-22s %s → %s\n", alias.RiotID, formatSeen(alias.FirstSeen), formatSeen(alias.LastSeen))
+		}
+	}
+
+	if len(unknownAliases) > 0 {
+		fmt.Printf("\n💡 Nombres vistos que no están en el archivo de jugadores:\n")
+		players := make([]string, 0, len(unknownAliases))
+		for player := range unknownAliases {
+			players = append(players, player)
+		}
+		sort.Strings(players)
+		for _, player := range players {
+			fmt.Printf("   %-12s %s\n", player, strings.Join(unknownAliases[player], ", "))
+		}
+	}
+}
+
+// formatSeen formatea un timestamp del historial de nombres
+func formatSeen(timestamp int64) string {
+	if timestamp == 0 {
+		return "?"
+	}
+	return time.Unix(timestamp, 0).Format("2006-01-02")
+}
+
+// SaveStats guarda el análisis de estadísticas de todo el stack
+func SaveStats(path, window string, stackStats []*models.PlayerStats, matches []models.MatchData, matchRatings map[string][]analytics.PlayerRating) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "Ventana: %s\n", window)
+	fmt.Fprintf(f, "Partidas analizadas: %d\n\n", len(matches))
+	writeStackTable(f, stackStats)
+	PrintMatchRatings(f, matches, matchRatings, 0)
+
+	for _, stats := range stackStats {
+		fmt.Fprintln(f)
+		writePlayerStats(f, stats)
+	}
+
+	return nil
+}
+
+// writePlayerStats escribe el bloque de estadísticas de un jugador
+func writePlayerStats(f io.Writer, stats *models.PlayerStats) {
+	fmt.Fprintf(f, "[%s]\n", stats.Name)
+	fmt.Fprintf(f, "Partidas: %d | Victorias: %d | Derrotas: %d | WR: %.1f%%\n",
+		stats.TotalGames, stats.Wins, stats.Losses, analytics.WinRate(stats))
+	fmt.Fprintf(f, "K/D/A: %d/%d/%d | KDA: %.2f | +/-: %d\n",
+		stats.Kills, stats.Deaths, stats.Assists,
+		float64(stats.Kills+stats.Assists)/float64(stats.Deaths+1),
+		stats.Kills-stats.Deaths)
+
+	fmt.Fprintf(f, "Promedios: %.1f/%.1f/%.1f por partida\n",
+		float64(stats.Kills)/float64(stats.TotalGames+1),
+		float64(stats.Deaths)/float64(stats.TotalGames+1),
+		float64(stats.Assists)/float64(stats.TotalGames+1))
+
+	fmt.Fprintf(f, "Rating: %.2f | ACS: %.2f | ADR: %.2f\n", stats.Rating, analytics.ACS(stats), analytics.ADR(stats))
+
+	fmt.Fprintf(f, "FK/FD: %d/%d (%.1f%%)\n",
+		stats.FirstKills, stats.FirstDeaths,
+		float64(stats.FirstKills)*100/float64(stats.FirstKills+stats.FirstDeaths+1))
+
+	kastPct := analytics.KASTPct(stats)
+	if kastPct >= 60 {
+		fmt.Fprintf(f, "KAST: %.1f%% [OK] (%d rondas)\n", kastPct, stats.KASTRounds)
+	} else {
+		fmt.Fprintf(f, "KAST: %.1f%% [LOW] (%d rondas)\n", kastPct, stats.KASTRounds)
+	}
+
+	fmt.Fprintf(f, "Multi-Kills: 2K: %d | 3K: %d | 4K: %d | 5K: %d\n",
+		stats.MultiKills[2], stats.MultiKills[3], stats.MultiKills[4], stats.MultiKills[5])
+
+	clutches := analytics.ClutchTotal(stats)
+	fmt.Fprintf(f, "Clutches: %d/%d (%.1f%%)", clutches.Wins, clutches.Rounds, clutches.WinRate())
+	for _, record := range analytics.ClutchRecords(stats) {
+		fmt.Fprintf(f, " | %s: %d/%d", record.Name, record.Wins, record.Rounds)
+	}
+	fmt.Fprintln(f)
+}
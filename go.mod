@@ -2,4 +2,9 @@ module valo-track
 
 go 1.21
 
-require github.com/yldshv/go-valorant-api v1.0.7
+require (
+	go.etcd.io/bbolt v1.3.10
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.4.0 // indirect
@@ -0,0 +1,436 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"valo-track/internal/api"
+	"valo-track/internal/config"
+)
+
+// resolveRetryAfter es cuánto esperar antes de volver a consultar un Riot ID que no se
+// pudo resolver (suelen ser alias viejos que ya no existen)
+const resolveRetryAfter = 24 * time.Hour
+
+// Alias es un name#tag visto para una cuenta
+type Alias struct {
+	RiotID    string `json:"riot_id"`
+	FirstSeen int64  `json:"first_seen"` // Segundos Unix de la primera partida con este nombre
+	LastSeen  int64  `json:"last_seen"`
+}
+
+// Account es una cuenta de Valorant identificada por PUUID
+type Account struct {
+	PUUID   string  `json:"puuid"`
+	Player  string  `json:"player"`  // Nombre real de la persona del stack
+	RiotID  string  `json:"riot_id"` // Último name#tag conocido
+	Aliases []Alias `json:"aliases"`
+}
+
+// Rename es un cambio de name#tag detectado para una cuenta
+type Rename struct {
+	PUUID  string
+	Player string
+	From   string
+	To     string
+}
+
+// PUUIDResolver obtiene el PUUID de un Riot ID (lo implementa api.APIClient)
+type PUUIDResolver interface {
+	GetPlayerPUUID(ctx context.Context, name, tag string) (string, error)
+}
+
+// registryFile es el formato del cache en disco
+type registryFile struct {
+	Accounts   []*Account       `json:"accounts"`
+	Unresolved map[string]int64 `json:"unresolved,omitempty"` // Riot ID -> último intento fallido
+}
+
+// Registry identifica a las cuentas del stack por PUUID y guarda el historial de
+// nombres de cada una, para que los renombres no corten el histórico
+type Registry struct {
+	path string
+
+	mu         sync.Mutex
+	accounts   map[string]*Account // PUUID -> cuenta
+	configured map[string]string   // Riot ID en minúsculas -> nombre real
+	unresolved map[string]int64
+	renames    []Rename
+	dirty      bool
+	readOnly   bool
+}
+
+// NewRegistry carga el cache de cuentas desde path (si existe) y le suma los PUUIDs
+// definidos en el roster
+func NewRegistry(path string, players []config.PlayerProfile) (*Registry, error) {
+	r := &Registry{
+		path:       path,
+		accounts:   make(map[string]*Account),
+		configured: make(map[string]string),
+		unresolved: make(map[string]int64),
+	}
+
+	for _, player := range players {
+		name := strings.TrimSpace(player.Name)
+		for _, riotID := range player.RiotIDs {
+			r.configured[config.RiotIDKey(riotID)] = name
+		}
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	// Los PUUIDs del roster mandan sobre el cache
+	for _, player := range players {
+		name := strings.TrimSpace(player.Name)
+		for _, puuid := range player.PUUIDs {
+			puuid = strings.TrimSpace(puuid)
+			account, ok := r.accounts[puuid]
+			if !ok {
+				r.accounts[puuid] = &Account{PUUID: puuid, Player: name}
+				r.dirty = true
+				continue
+			}
+			if account.Player != name {
+				account.Player = name
+				r.dirty = true
+			}
+		}
+	}
+
+	// Descartar cuentas de personas que ya no están en el roster
+	stackPlayers := make(map[string]bool)
+	for _, name := range r.configured {
+		stackPlayers[name] = true
+	}
+	for puuid, account := range r.accounts {
+		if !stackPlayers[account.Player] {
+			delete(r.accounts, puuid)
+			r.dirty = true
+		}
+	}
+
+	return r, nil
+}
+
+// load lee el cache de disco; un archivo inexistente no es un error
+func (r *Registry) load() error {
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error leyendo registro de cuentas: %w", err)
+	}
+
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("error decodificando %s: %w", r.path, err)
+	}
+
+	for _, account := range file.Accounts {
+		if account.PUUID != "" {
+			r.accounts[account.PUUID] = account
+		}
+	}
+	for riotID, attempt := range file.Unresolved {
+		r.unresolved[riotID] = attempt
+	}
+
+	return nil
+}
+
+// SetReadOnly hace que Save no escriba a disco: lo aprendido en la ejecución queda solo
+// en memoria. Sirve para no mezclar en el cache PUUIDs de fixtures con los reales.
+func (r *Registry) SetReadOnly(readOnly bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readOnly = readOnly
+}
+
+// Save escribe el cache a disco si hubo cambios
+func (r *Registry) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.dirty || r.readOnly {
+		return nil
+	}
+
+	file := registryFile{
+		Accounts:   make([]*Account, 0, len(r.accounts)),
+		Unresolved: r.unresolved,
+	}
+	for _, account := range r.accounts {
+		file.Accounts = append(file.Accounts, account)
+	}
+	sort.Slice(file.Accounts, func(i, j int) bool {
+		if file.Accounts[i].Player != file.Accounts[j].Player {
+			return file.Accounts[i].Player < file.Accounts[j].Player
+		}
+		return file.Accounts[i].PUUID < file.Accounts[j].PUUID
+	})
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("error creando directorio del registro de cuentas: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("error guardando registro de cuentas: %w", err)
+	}
+
+	r.dirty = false
+	return nil
+}
+
+// Resolve consulta el PUUID de cada Riot ID del roster que todavía no está asociado a
+// una cuenta. Retorna cuántos se resolvieron y los errores de los que fallaron. Si se
+// cancela ctx se corta sin marcar como fallido el Riot ID en curso (ver ctx.Err()), y con
+// una key inválida se corta en el primer error en vez de gastar una request por alias.
+// Solo se marca como fallido (y no se reintenta por un tiempo) si la API dice que no
+// existe: una key inválida, una caída de la API o un PUUID que ya es de otra persona no
+// deben esconder el alias.
+func (r *Registry) Resolve(ctx context.Context, resolver PUUIDResolver, players []config.PlayerProfile) (int, []error) {
+	resolved := 0
+	var failures []error
+
+	now := time.Now().Unix()
+	for _, player := range players {
+		name := strings.TrimSpace(player.Name)
+		for _, riotID := range player.RiotIDs {
+			if !r.needsResolve(riotID, now) {
+				continue
+			}
+
+			gameName, tag, _ := strings.Cut(riotID, "#")
+			puuid, err := resolver.GetPlayerPUUID(ctx, gameName, tag)
+			if ctx.Err() != nil {
+				return resolved, failures
+			}
+			if err == nil && puuid == "" {
+				err = fmt.Errorf("la API no retornó PUUID: %w", api.ErrNotFound)
+			}
+			if err != nil {
+				if errors.Is(err, api.ErrNotFound) {
+					r.markUnresolved(riotID, now)
+				}
+				failures = append(failures, fmt.Errorf("%s (%s): %w", riotID, name, err))
+				if errors.Is(err, api.ErrUnauthorized) {
+					return resolved, failures
+				}
+				continue
+			}
+
+			if err := r.bind(puuid, name, riotID, now); err != nil {
+				failures = append(failures, fmt.Errorf("%s (%s): %w", riotID, name, err))
+				continue
+			}
+			resolved++
+		}
+	}
+
+	return resolved, failures
+}
+
+// needsResolve indica si el Riot ID no tiene cuenta conocida y no falló hace poco
+func (r *Registry) needsResolve(riotID string, now int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.aliasOwner(riotID) != nil {
+		return false
+	}
+
+	if attempt, ok := r.unresolved[riotID]; ok && now-attempt < int64(resolveRetryAfter.Seconds()) {
+		return false
+	}
+	return true
+}
+
+func (r *Registry) markUnresolved(riotID string, now int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unresolved[riotID] = now
+	r.dirty = true
+}
+
+// bind asocia un PUUID a una persona. Falla si el PUUID ya pertenece a otra persona
+// (por ejemplo, un alias viejo que hoy usa otra cuenta).
+func (r *Registry) bind(puuid, player, riotID string, timestamp int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.accounts[puuid]
+	if ok && account.Player != player {
+		return fmt.Errorf("el PUUID %s ya pertenece a %s", puuid, account.Player)
+	}
+	if !ok {
+		account = &Account{PUUID: puuid, Player: player}
+		r.accounts[puuid] = account
+	}
+
+	r.observe(account, riotID, timestamp)
+	delete(r.unresolved, riotID)
+	r.dirty = true
+	return nil
+}
+
+// Lookup retorna el nombre real del dueño de una cuenta vista en una partida, o vacío
+// si no es del stack. Primero busca por PUUID; si no lo conoce, usa el Riot ID del
+// roster y aprende el PUUID. Registra el name#tag visto y detecta renombres.
+func (r *Registry) Lookup(puuid, name, tag string, timestamp int64) string {
+	riotID := fmt.Sprintf("%s#%s", name, tag)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if account, ok := r.accounts[puuid]; ok {
+		r.observe(account, riotID, timestamp)
+		return account.Player
+	}
+
+	player, ok := r.configured[config.RiotIDKey(riotID)]
+	if !ok || puuid == "" {
+		return player
+	}
+
+	// Si el Riot ID ya lo usó otra cuenta conocida, ahora lo tiene otra persona
+	if r.aliasOwner(riotID) != nil {
+		return ""
+	}
+
+	account := &Account{PUUID: puuid, Player: player}
+	r.accounts[puuid] = account
+	r.observe(account, riotID, timestamp)
+	return player
+}
+
+// observe registra que la cuenta usó riotID en timestamp. Debe llamarse con mu tomado.
+func (r *Registry) observe(account *Account, riotID string, timestamp int64) {
+	for i := range account.Aliases {
+		alias := &account.Aliases[i]
+		if alias.RiotID != riotID {
+			continue
+		}
+		if timestamp > 0 && (alias.FirstSeen == 0 || timestamp < alias.FirstSeen) {
+			alias.FirstSeen = timestamp
+			r.dirty = true
+		}
+		if timestamp > alias.LastSeen {
+			alias.LastSeen = timestamp
+			r.dirty = true
+		}
+		r.refreshCurrent(account)
+		return
+	}
+
+	// Nombre nuevo para la cuenta: si ya tenía otros, es un renombre. Si la partida es
+	// anterior a la última vez que se vio el nombre actual, el renombre fue al revés.
+	if len(account.Aliases) > 0 {
+		rename := Rename{PUUID: account.PUUID, Player: account.Player, From: account.RiotID, To: riotID}
+		if timestamp > 0 && timestamp < r.lastSeen(account) {
+			rename.From, rename.To = riotID, account.RiotID
+		}
+		r.renames = append(r.renames, rename)
+	}
+
+	account.Aliases = append(account.Aliases, Alias{RiotID: riotID, FirstSeen: timestamp, LastSeen: timestamp})
+	r.refreshCurrent(account)
+	r.dirty = true
+}
+
+// aliasOwner retorna la cuenta que usó riotID, o nil. Debe llamarse con mu tomado.
+func (r *Registry) aliasOwner(riotID string) *Account {
+	for _, account := range r.accounts {
+		for _, alias := range account.Aliases {
+			if strings.EqualFold(alias.RiotID, riotID) {
+				return account
+			}
+		}
+	}
+	return nil
+}
+
+// lastSeen retorna la última vez que se vio la cuenta con cualquier nombre
+func (r *Registry) lastSeen(account *Account) int64 {
+	last := int64(0)
+	for _, alias := range account.Aliases {
+		if alias.LastSeen > last {
+			last = alias.LastSeen
+		}
+	}
+	return last
+}
+
+// refreshCurrent deja como RiotID actual el alias visto más recientemente
+func (r *Registry) refreshCurrent(account *Account) {
+	current := account.Aliases[0]
+	for _, alias := range account.Aliases[1:] {
+		if alias.LastSeen >= current.LastSeen {
+			current = alias
+		}
+	}
+	account.RiotID = current.RiotID
+}
+
+// Renames retorna los renombres detectados desde que se cargó el registro
+func (r *Registry) Renames() []Rename {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	renames := make([]Rename, len(r.renames))
+	copy(renames, r.renames)
+	return renames
+}
+
+// UnknownAliases retorna, por persona, los name#tag vistos que no están en el roster,
+// para poder agregarlos al archivo de jugadores
+func (r *Registry) UnknownAliases() map[string][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unknown := make(map[string][]string)
+	for _, account := range r.accounts {
+		for _, alias := range account.Aliases {
+			if _, ok := r.configured[config.RiotIDKey(alias.RiotID)]; !ok {
+				unknown[account.Player] = append(unknown[account.Player], alias.RiotID)
+			}
+		}
+	}
+	for player := range unknown {
+		sort.Strings(unknown[player])
+	}
+	return unknown
+}
+
+// Accounts retorna una copia de las cuentas conocidas, ordenadas por persona
+func (r *Registry) Accounts() []Account {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accounts := make([]Account, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		copied := *account
+		copied.Aliases = append([]Alias(nil), account.Aliases...)
+		accounts = append(accounts, copied)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Player != accounts[j].Player {
+			return accounts[i].Player < accounts[j].Player
+		}
+		return accounts[i].RiotID < accounts[j].RiotID
+	})
+	return accounts
+}
@@ -0,0 +1,266 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"valo-track/internal/api"
+	"valo-track/internal/config"
+)
+
+// fakeResolver responde PUUIDs desde un mapa Riot ID -> PUUID y cuenta las consultas
+type fakeResolver struct {
+	puuids map[string]string
+	calls  []string
+}
+
+func (f *fakeResolver) GetPlayerPUUID(ctx context.Context, name, tag string) (string, error) {
+	riotID := name + "#" + tag
+	f.calls = append(f.calls, riotID)
+	if puuid, ok := f.puuids[riotID]; ok {
+		return puuid, nil
+	}
+	return "", fmt.Errorf("%s: %w", riotID, api.ErrNotFound)
+}
+
+var testPlayers = []config.PlayerProfile{
+	{Name: "Rosarino", RiotIDs: []string{"Rosarino#CARC", "Ros#viejo"}},
+	{Name: "Santi", RiotIDs: []string{"Lessツ#2222"}},
+}
+
+// newTestRegistry crea un registro vacío en un directorio temporal
+func newTestRegistry(t *testing.T, players []config.PlayerProfile) *Registry {
+	t.Helper()
+	r, err := NewRegistry(filepath.Join(t.TempDir(), "accounts.json"), players)
+	if err != nil {
+		t.Fatalf("error creando registro: %v", err)
+	}
+	return r
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *Registry)
+		puuid string
+		riot  [2]string
+		want  string
+	}{
+		{
+			name:  "Riot ID del roster aprende el PUUID",
+			puuid: "p-ros", riot: [2]string{"Rosarino", "CARC"}, want: "Rosarino",
+		},
+		{
+			name:  "sin distinguir mayúsculas",
+			puuid: "p-ros", riot: [2]string{"ROSARINO", "carc"}, want: "Rosarino",
+		},
+		{
+			name:  "desconocido no es del stack",
+			puuid: "p-otro", riot: [2]string{"Rival", "EUW"}, want: "",
+		},
+		{
+			name: "PUUID conocido con nombre nuevo",
+			setup: func(r *Registry) {
+				r.Lookup("p-ros", "Rosarino", "CARC", 100)
+			},
+			puuid: "p-ros", riot: [2]string{"Nuevo", "NAME"}, want: "Rosarino",
+		},
+		{
+			name: "alias viejo que hoy usa otra cuenta",
+			setup: func(r *Registry) {
+				// Rosarino se cambió el nombre y otra cuenta tomó "Ros#viejo"
+				r.Lookup("p-ros", "Ros", "viejo", 100)
+				r.Lookup("p-ros", "Rosarino", "CARC", 200)
+			},
+			puuid: "p-ajeno", riot: [2]string{"Ros", "viejo"}, want: "",
+		},
+		{
+			name: "alias de la cuenta de otra persona",
+			setup: func(r *Registry) {
+				// El PUUID de Santi se vio con un Riot ID del roster de Rosarino
+				r.Lookup("p-santi", "Lessツ", "2222", 100)
+				r.Lookup("p-santi", "Ros", "viejo", 200)
+			},
+			puuid: "p-ajeno", riot: [2]string{"ros", "VIEJO"}, want: "",
+		},
+		{
+			name:  "sin PUUID usa solo el roster",
+			puuid: "", riot: [2]string{"Lessツ", "2222"}, want: "Santi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, testPlayers)
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+			if got := r.Lookup(tt.puuid, tt.riot[0], tt.riot[1], 300); got != tt.want {
+				t.Errorf("Lookup(%q, %s#%s) = %q, se esperaba %q", tt.puuid, tt.riot[0], tt.riot[1], got, tt.want)
+			}
+		})
+	}
+
+	// Un alias ajeno no se aprende: la cuenta desconocida no queda en el registro
+	r := newTestRegistry(t, testPlayers)
+	r.Lookup("p-ros", "Ros", "viejo", 100)
+	r.Lookup("p-ros", "Rosarino", "CARC", 200)
+	r.Lookup("p-ajeno", "Ros", "viejo", 300)
+	if accounts := r.Accounts(); len(accounts) != 1 || accounts[0].PUUID != "p-ros" {
+		t.Errorf("Accounts() = %+v, se esperaba solo p-ros", accounts)
+	}
+}
+
+func TestObserveRenameDirection(t *testing.T) {
+	tests := []struct {
+		name        string
+		first       int64 // Partida con Rosarino#CARC
+		second      int64 // Partida con Nuevo#NAME
+		wantFrom    string
+		wantTo      string
+		wantCurrent string
+	}{
+		{"partida nueva con otro nombre", 100, 200, "Rosarino#CARC", "Nuevo#NAME", "Nuevo#NAME"},
+		// Se procesa después una partida anterior: el renombre fue al revés
+		{"partida vieja procesada después", 200, 100, "Nuevo#NAME", "Rosarino#CARC", "Rosarino#CARC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, testPlayers)
+			r.Lookup("p-ros", "Rosarino", "CARC", tt.first)
+			r.Lookup("p-ros", "Nuevo", "NAME", tt.second)
+
+			renames := r.Renames()
+			if len(renames) != 1 {
+				t.Fatalf("Renames() = %+v, se esperaba 1", renames)
+			}
+			if renames[0].From != tt.wantFrom || renames[0].To != tt.wantTo || renames[0].Player != "Rosarino" {
+				t.Errorf("Rename = %+v, se esperaba %s -> %s", renames[0], tt.wantFrom, tt.wantTo)
+			}
+			if current := r.Accounts()[0].RiotID; current != tt.wantCurrent {
+				t.Errorf("RiotID actual = %s, se esperaba %s", current, tt.wantCurrent)
+			}
+		})
+	}
+
+	// Volver a ver un nombre conocido no es un renombre
+	r := newTestRegistry(t, testPlayers)
+	r.Lookup("p-ros", "Rosarino", "CARC", 100)
+	r.Lookup("p-ros", "Rosarino", "CARC", 200)
+	if renames := r.Renames(); len(renames) != 0 {
+		t.Errorf("Renames() = %+v, se esperaba ninguno", renames)
+	}
+	if alias := r.Accounts()[0].Aliases[0]; alias.FirstSeen != 100 || alias.LastSeen != 200 {
+		t.Errorf("alias = %+v, se esperaba visto de 100 a 200", alias)
+	}
+}
+
+func TestResolveBindConflict(t *testing.T) {
+	r := newTestRegistry(t, testPlayers)
+
+	// Todos los Riot IDs devuelven la cuenta de Rosarino (como hace un fixture)
+	resolver := &fakeResolver{puuids: map[string]string{
+		"Rosarino#CARC": "p-ros",
+		"Ros#viejo":     "p-ros",
+		"Lessツ#2222":    "p-ros",
+	}}
+
+	// Los dos alias de Rosarino se asocian a su cuenta; el de Santi choca
+	resolved, failures := r.Resolve(context.Background(), resolver, testPlayers)
+	if resolved != 2 {
+		t.Errorf("resueltos = %d, se esperaban 2", resolved)
+	}
+	if len(failures) != 1 || !strings.Contains(failures[0].Error(), "ya pertenece a Rosarino") {
+		t.Fatalf("fallos = %v, se esperaba el conflicto de Lessツ#2222", failures)
+	}
+
+	// El conflicto no esconde el alias de Santi: se vuelve a consultar en el próximo Resolve
+	if _, ok := r.unresolved["Lessツ#2222"]; ok {
+		t.Error("el alias en conflicto quedó marcado como no resuelto")
+	}
+	resolver.puuids["Lessツ#2222"] = "p-santi"
+	resolver.calls = nil
+	if resolved, failures := r.Resolve(context.Background(), resolver, testPlayers); resolved != 1 || len(failures) != 0 {
+		t.Errorf("segundo Resolve = %d, %v; se esperaba 1 y sin fallos", resolved, failures)
+	}
+	if strings.Join(resolver.calls, ",") != "Lessツ#2222" {
+		t.Errorf("consultas = %v, se esperaba solo Lessツ#2222", resolver.calls)
+	}
+	if got := r.Lookup("p-santi", "Lessツ", "2222", 100); got != "Santi" {
+		t.Errorf("Lookup(p-santi) = %q, se esperaba Santi", got)
+	}
+}
+
+func TestResolveUnresolvedTTL(t *testing.T) {
+	now := time.Now().Unix()
+	tests := []struct {
+		name      string
+		attempt   int64 // Último intento fallido de Ros#viejo (0 = nunca)
+		wantQuery bool
+	}{
+		{"nunca consultado", 0, true},
+		{"falló hace una hora", now - 3600, false},
+		{"falló justo antes del vencimiento", now - int64(resolveRetryAfter.Seconds()) + 60, false},
+		{"el intento fallido venció", now - int64(resolveRetryAfter.Seconds()) - 60, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, testPlayers)
+			if tt.attempt != 0 {
+				r.unresolved["Ros#viejo"] = tt.attempt
+			}
+
+			resolver := &fakeResolver{puuids: map[string]string{}}
+			r.Resolve(context.Background(), resolver, testPlayers[:1])
+
+			queried := strings.Contains(strings.Join(resolver.calls, ","), "Ros#viejo")
+			if queried != tt.wantQuery {
+				t.Errorf("consultó Ros#viejo = %v, se esperaba %v", queried, tt.wantQuery)
+			}
+			// Un 404 renueva el intento fallido
+			if tt.wantQuery && r.unresolved["Ros#viejo"] < now {
+				t.Errorf("intento fallido = %d, se esperaba renovado", r.unresolved["Ros#viejo"])
+			}
+		})
+	}
+}
+
+func TestSaveReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	r, err := NewRegistry(path, testPlayers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.SetReadOnly(true)
+	r.Lookup("p-fixture", "Rosarino", "CARC", 100)
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Save() en modo solo lectura escribió %s", path)
+	}
+
+	r.SetReadOnly(false)
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Accounts) != 1 || file.Accounts[0].PUUID != "p-fixture" {
+		t.Errorf("cuentas guardadas = %+v, se esperaba p-fixture", file.Accounts)
+	}
+}
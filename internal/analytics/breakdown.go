@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"sort"
+	"valo-track/internal/models"
+)
+
+// SplitStats son las estadísticas completas de un jugador dentro de un corte
+type SplitStats struct {
+	Map   string // Vacío en los cortes solo por agente
+	Agent string // Vacío en los cortes solo por mapa
+	Stats *models.PlayerStats
+}
+
+// Label retorna el nombre del corte (Mapa, Agente o Mapa/Agente)
+func (ss SplitStats) Label() string {
+	switch {
+	case ss.Map == "":
+		return ss.Agent
+	case ss.Agent == "":
+		return ss.Map
+	default:
+		return ss.Map + " / " + ss.Agent
+	}
+}
+
+// Breakdown contiene los cortes por mapa, por agente y por mapa×agente de un jugador
+type Breakdown struct {
+	Player     *models.PlayerStats
+	ByMap      []SplitStats
+	ByAgent    []SplitStats
+	ByMapAgent []SplitStats
+}
+
+// Breakdown consolida las estadísticas de una persona del stack agrupadas por mapa,
+// por agente y por mapa×agente. Cada grupo se ordena por partidas jugadas.
+func (as *AnalyticsService) Breakdown(matches []models.MatchData, name string) (*Breakdown, error) {
+	player, err := as.ResolveStackName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	played := filterMatches(matches, func(match *models.MatchData) bool {
+		_, ok := match.PlayerData[player]
+		return ok
+	})
+
+	breakdown := &Breakdown{Player: as.analyzeNamed(played, player)}
+
+	maps := make(map[string]bool)
+	agents := make(map[string]bool)
+	mapAgents := make(map[[2]string]bool)
+	for i := range played {
+		mapName, agent := played[i].Map, played[i].PlayerData[player].Agent
+		if mapName != "" {
+			maps[mapName] = true
+		}
+		if agent != "" {
+			agents[agent] = true
+		}
+		if mapName != "" && agent != "" {
+			mapAgents[[2]string{mapName, agent}] = true
+		}
+	}
+
+	for mapName := range maps {
+		byMap := filterMatches(played, func(match *models.MatchData) bool {
+			return match.Map == mapName
+		})
+		breakdown.ByMap = append(breakdown.ByMap, SplitStats{Map: mapName, Stats: as.analyzeNamed(byMap, player)})
+	}
+
+	for agent := range agents {
+		breakdown.ByAgent = append(breakdown.ByAgent, SplitStats{
+			Agent: agent,
+			Stats: as.analyzeNamed(filterByAgent(played, player, agent), player),
+		})
+	}
+
+	for key := range mapAgents {
+		mapName, agent := key[0], key[1]
+		byMapAgent := filterMatches(filterByAgent(played, player, agent), func(match *models.MatchData) bool {
+			return match.Map == mapName
+		})
+		breakdown.ByMapAgent = append(breakdown.ByMapAgent, SplitStats{
+			Map:   mapName,
+			Agent: agent,
+			Stats: as.analyzeNamed(byMapAgent, player),
+		})
+	}
+
+	sortSplits(breakdown.ByMap)
+	sortSplits(breakdown.ByAgent)
+	sortSplits(breakdown.ByMapAgent)
+
+	return breakdown, nil
+}
+
+// sortSplits ordena los cortes por partidas jugadas y luego por nombre
+func sortSplits(splits []SplitStats) {
+	sort.Slice(splits, func(i, j int) bool {
+		if splits[i].Stats.TotalGames != splits[j].Stats.TotalGames {
+			return splits[i].Stats.TotalGames > splits[j].Stats.TotalGames
+		}
+		return splits[i].Label() < splits[j].Label()
+	})
+}
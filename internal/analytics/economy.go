@@ -0,0 +1,172 @@
+package analytics
+
+import "valo-track/internal/models"
+
+// Tipos de compra de un equipo en una ronda
+const (
+	BuyPistol = "pistol"
+	BuyEco    = "eco"
+	BuyForce  = "force"
+	BuyHalf   = "half"
+	BuyFull   = "full"
+)
+
+// BuyTypes son los tipos de compra en orden de presentación
+var BuyTypes = []string{BuyPistol, BuyEco, BuyForce, BuyHalf, BuyFull}
+
+// Umbrales por jugador (promedio del equipo) para clasificar la compra
+const (
+	ecoMaxLoadout       = 2000 // Menos que esto es eco (pistola/shorty y poco más)
+	fullBuyMinLoadout   = 3900 // Rifle + escudo pesado
+	forceMaxRemaining   = 1000 // Compra intermedia quedando sin plata = force
+	OutBoughtMargin     = 2000 // Diferencia de loadout (equipo) para considerar que el rival compró más
+	pistolRoundsPerHalf = 12
+)
+
+// ClassifyBuy clasifica la compra de un equipo. roundIndex es la posición de la ronda
+// en la partida (0 y 12 son pistolas).
+func ClassifyBuy(roundIndex int, economy models.TeamEconomy) string {
+	if roundIndex == 0 || roundIndex == pistolRoundsPerHalf {
+		return BuyPistol
+	}
+	if economy.Players == 0 {
+		return ""
+	}
+
+	avgLoadout := economy.Loadout / economy.Players
+	avgRemaining := economy.Remaining / economy.Players
+	switch {
+	case avgLoadout < ecoMaxLoadout:
+		return BuyEco
+	case avgLoadout >= fullBuyMinLoadout:
+		return BuyFull
+	case avgRemaining < forceMaxRemaining:
+		return BuyForce
+	default:
+		return BuyHalf
+	}
+}
+
+// BuildTeamEconomy suma la economía de cada equipo en una ronda y clasifica su compra.
+// Retorna nil si la ronda no trae datos de economía.
+func (as *AnalyticsService) BuildTeamEconomy(roundIndex int, round models.V4Round) map[string]models.TeamEconomy {
+	teams := make(map[string]models.TeamEconomy)
+	for _, stat := range round.Stats {
+		if stat.Economy == nil {
+			continue
+		}
+		team := teams[stat.Player.Team]
+		team.Loadout += stat.Economy.LoadoutValue
+		team.Spent += stat.Economy.Spent
+		team.Remaining += stat.Economy.Remaining
+		team.Players++
+		teams[stat.Player.Team] = team
+	}
+
+	if len(teams) == 0 {
+		return nil
+	}
+
+	for teamID, team := range teams {
+		team.Buy = ClassifyBuy(roundIndex, team)
+		teams[teamID] = team
+	}
+	return teams
+}
+
+// CalculateEconomy acumula por jugador del stack el valor de equipamiento, los créditos
+// gastados y el daño hecho en las rondas con datos de economía
+func (as *AnalyticsService) CalculateEconomy(rounds []models.V4Round, match *models.MatchData, stackNamesByPUUID map[string]string) {
+	for _, round := range rounds {
+		for _, stat := range round.Stats {
+			playerName, ok := stackNamesByPUUID[stat.Player.PUUID]
+			if !ok || stat.Economy == nil {
+				continue
+			}
+			match.LoadoutValue[playerName] += stat.Economy.LoadoutValue
+			match.CreditsSpent[playerName] += stat.Economy.Spent
+			match.EconomyDamage[playerName] += stat.Stats.Damage
+		}
+	}
+}
+
+// EconomyReport resume el rendimiento del stack según la economía de cada ronda
+type EconomyReport struct {
+	Rounds      int           // Rondas con datos de economía de ambos equipos
+	ByBuy       []RoundRecord // Según la compra del stack, en el orden de BuyTypes
+	OutBought   RoundRecord   // Rondas en las que el rival compró OutBoughtMargin o más
+	LoadoutDiff int           // Suma de (loadout del stack - loadout rival)
+}
+
+// AverageLoadoutDiff retorna la diferencia promedio de loadout por ronda (positiva = el stack compró más)
+func (er *EconomyReport) AverageLoadoutDiff() float64 {
+	return ratio(float64(er.LoadoutDiff), float64(er.Rounds))
+}
+
+// AnalyzeEconomy calcula win rate por tipo de compra, win rate comprando menos que el
+// rival y la diferencia de loadout promedio, desde el lado del stack
+func (as *AnalyticsService) AnalyzeEconomy(matches []models.MatchData) *EconomyReport {
+	byBuy := make(map[string]*RoundRecord, len(BuyTypes))
+	for _, buy := range BuyTypes {
+		byBuy[buy] = &RoundRecord{Name: buy}
+	}
+
+	report := &EconomyReport{OutBought: RoundRecord{Name: "out-bought"}}
+	for _, match := range matches {
+		stackTeam := StackTeam(&match)
+		if stackTeam == "" {
+			continue
+		}
+
+		for _, round := range match.Rounds {
+			own, ok := round.Economy[stackTeam]
+			if !ok {
+				continue
+			}
+			rival, ok := rivalEconomy(round.Economy, stackTeam)
+			if !ok {
+				continue
+			}
+
+			won := round.WinningTeam == stackTeam
+			report.Rounds++
+			report.LoadoutDiff += own.Loadout - rival.Loadout
+
+			if record, ok := byBuy[own.Buy]; ok {
+				record.Add(won)
+			}
+
+			if rival.Loadout-own.Loadout >= OutBoughtMargin {
+				report.OutBought.Add(won)
+			}
+		}
+	}
+
+	for _, buy := range BuyTypes {
+		report.ByBuy = append(report.ByBuy, *byBuy[buy])
+	}
+	return report
+}
+
+// StackTeam retorna el equipo en el que jugó el stack (el más común entre sus jugadores)
+func StackTeam(match *models.MatchData) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, team := range match.PlayerTeams {
+		counts[team]++
+		if counts[team] > counts[best] || (counts[team] == counts[best] && team < best) {
+			best = team
+		}
+	}
+	return best
+}
+
+// rivalEconomy retorna la economía del equipo que no es el del stack
+func rivalEconomy(economy map[string]models.TeamEconomy, stackTeam string) (models.TeamEconomy, bool) {
+	for teamID, team := range economy {
+		if teamID != stackTeam {
+			return team, true
+		}
+	}
+	return models.TeamEconomy{}, false
+}
@@ -0,0 +1,199 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"valo-track/internal/models"
+)
+
+// Metric define una métrica por la que se puede rankear al stack
+type Metric struct {
+	Key    string // Nombre usado en flags (-sort=acs)
+	Label  string
+	Format string // Formato para imprimir el valor
+	Value  func(stats *models.PlayerStats) float64
+}
+
+// LeaderboardMetrics son las métricas del leaderboard, en orden de presentación
+var LeaderboardMetrics = []Metric{
+	{Key: "rating", Label: "Rating", Format: "%.2f", Value: func(stats *models.PlayerStats) float64 {
+		return stats.Rating
+	}},
+	{Key: "acs", Label: "ACS", Format: "%.1f", Value: ACS},
+	{Key: "adr", Label: "ADR", Format: "%.1f", Value: ADR},
+	{Key: "kast", Label: "KAST%", Format: "%.1f", Value: KASTPct},
+	{Key: "kd", Label: "K/D", Format: "%.2f", Value: KD},
+	{Key: "fkfd", Label: "FK/FD", Format: "%.2f", Value: FKFDRatio},
+	{Key: "hs", Label: "HS%", Format: "%.1f", Value: HSPct},
+	{Key: "clutches", Label: "Clutches", Format: "%.0f", Value: func(stats *models.PlayerStats) float64 {
+		return float64(stats.Clutches)
+	}},
+	{Key: "multikills", Label: "Multi-kills", Format: "%.0f", Value: func(stats *models.PlayerStats) float64 {
+		return float64(MultiKillTotal(stats))
+	}},
+}
+
+// MetricByKey busca una métrica del leaderboard por su clave
+func MetricByKey(key string) (Metric, error) {
+	for _, metric := range LeaderboardMetrics {
+		if strings.EqualFold(metric.Key, key) {
+			return metric, nil
+		}
+	}
+
+	keys := make([]string, 0, len(LeaderboardMetrics))
+	for _, metric := range LeaderboardMetrics {
+		keys = append(keys, metric.Key)
+	}
+	return Metric{}, fmt.Errorf("métrica desconocida %q (opciones: %s)", key, strings.Join(keys, ", "))
+}
+
+// Leaderboard retorna una copia de los stats ordenada de mayor a menor por la métrica
+func Leaderboard(stackStats []*models.PlayerStats, metric Metric) []*models.PlayerStats {
+	ranked := make([]*models.PlayerStats, len(stackStats))
+	copy(ranked, stackStats)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return metric.Value(ranked[i]) > metric.Value(ranked[j])
+	})
+
+	return ranked
+}
+
+// Comparison contiene las estadísticas de dos jugadores y sus cortes por mapa y agente
+type Comparison struct {
+	A      *models.PlayerStats
+	B      *models.PlayerStats
+	Maps   []SplitComparison // Mapas que jugaron ambos
+	Agents []SplitComparison // Agentes que jugaron ambos
+}
+
+// SplitComparison compara a los dos jugadores dentro de un mapa o agente
+type SplitComparison struct {
+	Name string
+	A    *models.PlayerStats
+	B    *models.PlayerStats
+}
+
+// Better retorna el nombre de quien tiene mejor ACS en el corte (vacío si empatan)
+func (sc SplitComparison) Better() string {
+	acsA, acsB := ACS(sc.A), ACS(sc.B)
+	switch {
+	case acsA > acsB:
+		return sc.A.Name
+	case acsB > acsA:
+		return sc.B.Name
+	default:
+		return ""
+	}
+}
+
+// Compare construye la comparación entre dos personas del stack
+func (as *AnalyticsService) Compare(matches []models.MatchData, nameA, nameB string) (*Comparison, error) {
+	playerA, err := as.ResolveStackName(nameA)
+	if err != nil {
+		return nil, err
+	}
+	playerB, err := as.ResolveStackName(nameB)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &Comparison{
+		A: as.analyzeNamed(matches, playerA),
+		B: as.analyzeNamed(matches, playerB),
+	}
+
+	// Cortes por mapa
+	for _, mapName := range sharedKeys(matches, playerA, playerB, func(match *models.MatchData, _ string) string {
+		return match.Map
+	}) {
+		byMap := filterMatches(matches, func(match *models.MatchData) bool {
+			return match.Map == mapName
+		})
+		comparison.Maps = append(comparison.Maps, SplitComparison{
+			Name: mapName,
+			A:    as.analyzeNamed(byMap, playerA),
+			B:    as.analyzeNamed(byMap, playerB),
+		})
+	}
+
+	// Cortes por agente (cada uno con sus propias partidas en ese agente)
+	for _, agent := range sharedKeys(matches, playerA, playerB, func(match *models.MatchData, player string) string {
+		return match.PlayerData[player].Agent
+	}) {
+		comparison.Agents = append(comparison.Agents, SplitComparison{
+			Name: agent,
+			A:    as.analyzeNamed(filterByAgent(matches, playerA, agent), playerA),
+			B:    as.analyzeNamed(filterByAgent(matches, playerB, agent), playerB),
+		})
+	}
+
+	return comparison, nil
+}
+
+// ResolveStackName busca el nombre real de una persona del stack sin distinguir mayúsculas
+func (as *AnalyticsService) ResolveStackName(name string) (string, error) {
+	for _, stackName := range as.StackPlayerNames() {
+		if strings.EqualFold(stackName, name) {
+			return stackName, nil
+		}
+	}
+	return "", fmt.Errorf("%q no es un jugador del stack (opciones: %s)", name, strings.Join(as.StackPlayerNames(), ", "))
+}
+
+// analyzeNamed consolida las estadísticas de una persona y les asigna su nombre
+func (as *AnalyticsService) analyzeNamed(matches []models.MatchData, player string) *models.PlayerStats {
+	stats := as.AnalyzeMatches(matches, []string{player})
+	stats.Name = player
+	return stats
+}
+
+// filterMatches retorna las partidas que cumplen la condición
+func filterMatches(matches []models.MatchData, keep func(match *models.MatchData) bool) []models.MatchData {
+	filtered := make([]models.MatchData, 0)
+	for i := range matches {
+		if keep(&matches[i]) {
+			filtered = append(filtered, matches[i])
+		}
+	}
+	return filtered
+}
+
+// filterByAgent retorna las partidas en las que el jugador usó el agente
+func filterByAgent(matches []models.MatchData, player, agent string) []models.MatchData {
+	return filterMatches(matches, func(match *models.MatchData) bool {
+		stats, ok := match.PlayerData[player]
+		return ok && stats.Agent == agent
+	})
+}
+
+// sharedKeys retorna, ordenadas, las claves (mapa, agente...) en las que jugaron ambos jugadores
+func sharedKeys(matches []models.MatchData, playerA, playerB string, key func(match *models.MatchData, player string) string) []string {
+	seenA := make(map[string]bool)
+	seenB := make(map[string]bool)
+	for i := range matches {
+		match := &matches[i]
+		if _, ok := match.PlayerData[playerA]; ok {
+			if k := key(match, playerA); k != "" {
+				seenA[k] = true
+			}
+		}
+		if _, ok := match.PlayerData[playerB]; ok {
+			if k := key(match, playerB); k != "" {
+				seenB[k] = true
+			}
+		}
+	}
+
+	shared := make([]string, 0)
+	for k := range seenA {
+		if seenB[k] {
+			shared = append(shared, k)
+		}
+	}
+	sort.Strings(shared)
+
+	return shared
+}
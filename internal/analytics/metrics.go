@@ -0,0 +1,146 @@
+package analytics
+
+import (
+	"fmt"
+	"valo-track/internal/models"
+)
+
+// Métricas derivadas de PlayerStats. Todas retornan 0 cuando no hay datos.
+
+// WinRate retorna el porcentaje de partidas ganadas
+func WinRate(stats *models.PlayerStats) float64 {
+	return ratio(float64(stats.Wins)*100, float64(stats.TotalGames))
+}
+
+// KD retorna kills por muerte
+func KD(stats *models.PlayerStats) float64 {
+	if stats.Deaths == 0 {
+		return float64(stats.Kills)
+	}
+	return float64(stats.Kills) / float64(stats.Deaths)
+}
+
+// ACS retorna el Average Combat Score (score por ronda)
+func ACS(stats *models.PlayerStats) float64 {
+	return ratio(float64(stats.Score), float64(stats.TotalRounds))
+}
+
+// ADR retorna el daño promedio por ronda
+func ADR(stats *models.PlayerStats) float64 {
+	return ratio(float64(stats.DamageMade), float64(stats.TotalRounds))
+}
+
+// KASTPct retorna el porcentaje de rondas con Kill, Assist, Survive o Trade
+func KASTPct(stats *models.PlayerStats) float64 {
+	return ratio(float64(stats.KASTRounds)*100, float64(stats.TotalRounds))
+}
+
+// AttackADR retorna el daño promedio por ronda en ataque
+func AttackADR(stats *models.PlayerStats) float64 {
+	return ratio(float64(stats.AttackDamage), float64(stats.AttackRounds))
+}
+
+// DefenseADR retorna el daño promedio por ronda en defensa
+func DefenseADR(stats *models.PlayerStats) float64 {
+	return ratio(float64(stats.DefenseDamage), float64(stats.DefenseRounds))
+}
+
+// AttackKD retorna kills por muerte en ataque
+func AttackKD(stats *models.PlayerStats) float64 {
+	if stats.AttackDeaths == 0 {
+		return float64(stats.AttackKills)
+	}
+	return float64(stats.AttackKills) / float64(stats.AttackDeaths)
+}
+
+// DefenseKD retorna kills por muerte en defensa
+func DefenseKD(stats *models.PlayerStats) float64 {
+	if stats.DefenseDeaths == 0 {
+		return float64(stats.DefenseKills)
+	}
+	return float64(stats.DefenseKills) / float64(stats.DefenseDeaths)
+}
+
+// HSPct retorna el porcentaje de disparos a la cabeza
+func HSPct(stats *models.PlayerStats) float64 {
+	shots := stats.Headshots + stats.Bodyshots + stats.Legshots
+	return ratio(float64(stats.Headshots)*100, float64(shots))
+}
+
+// FKFDRatio retorna first kills por first death
+func FKFDRatio(stats *models.PlayerStats) float64 {
+	if stats.FirstDeaths == 0 {
+		return float64(stats.FirstKills)
+	}
+	return float64(stats.FirstKills) / float64(stats.FirstDeaths)
+}
+
+// DamagePer1000 retorna el daño hecho por cada 1000 créditos gastados
+func DamagePer1000(stats *models.PlayerStats) float64 {
+	return ratio(float64(stats.EconomyDamage)*1000, float64(stats.CreditsSpent))
+}
+
+// MultiKillTotal retorna la cantidad de rondas con 2 o más kills
+func MultiKillTotal(stats *models.PlayerStats) int {
+	total := 0
+	for count, occurrences := range stats.MultiKills {
+		if count >= 2 {
+			total += occurrences
+		}
+	}
+	return total
+}
+
+// MaxClutchEnemies es la situación de clutch más grande (1v5)
+const MaxClutchEnemies = 5
+
+// ClutchRecords retorna intentos (Rounds) y victorias de clutch de 1v1 a 1v5
+func ClutchRecords(stats *models.PlayerStats) []RoundRecord {
+	records := make([]RoundRecord, 0, MaxClutchEnemies)
+	for enemies := 1; enemies <= MaxClutchEnemies; enemies++ {
+		records = append(records, RoundRecord{
+			Name:   fmt.Sprintf("1v%d", enemies),
+			Rounds: stats.ClutchAttempts[enemies],
+			Wins:   stats.ClutchWins[enemies],
+		})
+	}
+	return records
+}
+
+// ClutchTotal retorna todas las situaciones de clutch juntas
+func ClutchTotal(stats *models.PlayerStats) RoundRecord {
+	total := RoundRecord{Name: "Total"}
+	for _, record := range ClutchRecords(stats) {
+		total.Rounds += record.Rounds
+		total.Wins += record.Wins
+	}
+	return total
+}
+
+// RoundRecord cuenta rondas jugadas y ganadas en alguna situación
+type RoundRecord struct {
+	Name   string
+	Rounds int
+	Wins   int
+}
+
+// Add suma una ronda al registro
+func (rr *RoundRecord) Add(won bool) {
+	rr.Rounds++
+	if won {
+		rr.Wins++
+	}
+}
+
+// WinRate retorna el porcentaje de rondas ganadas
+func (rr RoundRecord) WinRate() float64 {
+	return ratio(float64(rr.Wins)*100, float64(rr.Rounds))
+}
+
+// ratio divide evitando divisiones por cero
+func ratio(numerator, denominator float64) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
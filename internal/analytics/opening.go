@@ -0,0 +1,221 @@
+package analytics
+
+import (
+	"sort"
+	"valo-track/internal/models"
+)
+
+// Lados de una ronda desde el punto de vista de un jugador
+const (
+	SideAttack  = "attack"
+	SideDefense = "defense"
+)
+
+// Tramos de tiempo (en segundos desde el inicio de la ronda) para el primer contacto
+var firstContactBuckets = []struct {
+	Label string
+	MaxMs int
+}{
+	{"0-15s", 15000},
+	{"15-30s", 30000},
+	{"30-45s", 45000},
+	{"45-60s", 60000},
+	{"60-90s", 90000},
+	{"90s+", 0}, // Sin límite
+}
+
+// OpeningStats resume los duelos de apertura (primera kill de la ronda) de un jugador
+type OpeningStats struct {
+	Name         string      // Jugador o mapa
+	Duels        RoundRecord // Rounds = duelos de apertura, Wins = first kills
+	Attack       RoundRecord
+	Defense      RoundRecord
+	TradedDeaths int // First deaths que un compañero tradeó dentro de la ventana
+	TimeTotalMs  int // Suma del momento de cada duelo, para el promedio
+}
+
+// Deaths retorna los duelos de apertura perdidos (first deaths)
+func (st *OpeningStats) Deaths() int {
+	return st.Duels.Rounds - st.Duels.Wins
+}
+
+// TradedPct retorna el porcentaje de first deaths tradeadas
+func (st *OpeningStats) TradedPct() float64 {
+	return ratio(float64(st.TradedDeaths)*100, float64(st.Deaths()))
+}
+
+// AverageTime retorna el momento promedio del duelo de apertura, en segundos
+func (st *OpeningStats) AverageTime() float64 {
+	return ratio(float64(st.TimeTotalMs)/1000, float64(st.Duels.Rounds))
+}
+
+// addDuel suma un duelo de apertura
+func (st *OpeningStats) addDuel(won bool, side string, timeMs int, traded bool) {
+	st.Duels.Add(won)
+	st.TimeTotalMs += timeMs
+	if !won && traded {
+		st.TradedDeaths++
+	}
+
+	switch side {
+	case SideAttack:
+		st.Attack.Add(won)
+	case SideDefense:
+		st.Defense.Add(won)
+	}
+}
+
+// OpeningPlayer son los duelos de apertura de un jugador, en total y por mapa
+type OpeningPlayer struct {
+	OpeningStats
+	Maps []*OpeningStats // Ordenados por nombre de mapa
+}
+
+// OpeningReport contiene el análisis de entry del stack
+type OpeningReport struct {
+	Players         []*OpeningPlayer // Ordenados por duelos de apertura
+	AfterFirstKill  RoundRecord      // Rondas en las que el stack hizo la primera kill
+	AfterFirstDeath RoundRecord      // Rondas en las que el stack sufrió la primera muerte
+	FirstContact    []NamedCount     // Momento de la primera kill de cada ronda, por tramo
+}
+
+// AnalyzeOpenings calcula el win rate de duelos de apertura de cada jugador del stack por
+// mapa y lado, cuántas first deaths fueron tradeadas, el win rate de ronda según quién
+// hizo la primera kill y la distribución del momento del primer contacto
+func (as *AnalyticsService) AnalyzeOpenings(matches []models.MatchData) *OpeningReport {
+	players := make(map[string]*OpeningPlayer)
+	playerMaps := make(map[string]map[string]*OpeningStats)
+	contacts := make([]int, len(firstContactBuckets))
+
+	report := &OpeningReport{
+		AfterFirstKill:  RoundRecord{Name: "first kill"},
+		AfterFirstDeath: RoundRecord{Name: "first death"},
+	}
+
+	for _, match := range matches {
+		stackTeam := StackTeam(&match)
+		if stackTeam == "" {
+			continue
+		}
+
+		rounds := make(map[int]models.RoundData, len(match.Rounds))
+		for _, round := range match.Rounds {
+			rounds[round.Round] = round
+		}
+
+		eventsByRound := groupKillsByRound(match.Kills)
+		trades := as.ComputeTrades(eventsByRound)
+
+		for roundNumber, events := range eventsByRound {
+			first, ok := firstDuel(events)
+			if !ok {
+				continue
+			}
+			round := rounds[roundNumber]
+
+			contacts[firstContactBucket(first.Time)]++
+
+			switch stackTeam {
+			case first.KillerTeam:
+				report.AfterFirstKill.Add(round.WinningTeam == stackTeam)
+			case first.VictimTeam:
+				report.AfterFirstDeath.Add(round.WinningTeam == stackTeam)
+			}
+
+			for _, duel := range []struct {
+				name string
+				won  bool
+			}{{first.KillerName, true}, {first.VictimName, false}} {
+				if duel.name == "" {
+					continue
+				}
+
+				player, ok := players[duel.name]
+				if !ok {
+					player = &OpeningPlayer{OpeningStats: OpeningStats{Name: duel.name}}
+					players[duel.name] = player
+					playerMaps[duel.name] = make(map[string]*OpeningStats)
+				}
+				mapStats, ok := playerMaps[duel.name][match.Map]
+				if !ok {
+					mapStats = &OpeningStats{Name: match.Map}
+					playerMaps[duel.name][match.Map] = mapStats
+				}
+
+				side := playerSide(round, match.PlayerTeams[duel.name])
+				traded := trades[roundNumber][duel.name]
+				player.addDuel(duel.won, side, first.Time, traded)
+				mapStats.addDuel(duel.won, side, first.Time, traded)
+			}
+		}
+	}
+
+	for name, player := range players {
+		for _, mapStats := range playerMaps[name] {
+			player.Maps = append(player.Maps, mapStats)
+		}
+		sort.Slice(player.Maps, func(i, j int) bool {
+			return player.Maps[i].Name < player.Maps[j].Name
+		})
+		report.Players = append(report.Players, player)
+	}
+	sort.Slice(report.Players, func(i, j int) bool {
+		if report.Players[i].Duels.Rounds != report.Players[j].Duels.Rounds {
+			return report.Players[i].Duels.Rounds > report.Players[j].Duels.Rounds
+		}
+		return report.Players[i].Name < report.Players[j].Name
+	})
+
+	for i, bucket := range firstContactBuckets {
+		report.FirstContact = append(report.FirstContact, NamedCount{Name: bucket.Label, Count: contacts[i]})
+	}
+
+	return report
+}
+
+// firstContactBucket retorna el tramo de tiempo al que pertenece un momento de la ronda
+func firstContactBucket(timeMs int) int {
+	for i, bucket := range firstContactBuckets {
+		if bucket.MaxMs == 0 || timeMs < bucket.MaxMs {
+			return i
+		}
+	}
+	return len(firstContactBuckets) - 1
+}
+
+// playerSide retorna el lado del equipo en la ronda (vacío si no se conoce)
+func playerSide(round models.RoundData, team string) string {
+	switch {
+	case round.AttackingTeam == "" || team == "":
+		return ""
+	case round.AttackingTeam == team:
+		return SideAttack
+	default:
+		return SideDefense
+	}
+}
+
+// groupKillsByRound agrupa los eventos guardados de una partida por ronda, ordenados por tiempo
+func groupKillsByRound(kills []models.KillEvent) map[int][]models.KillEvent {
+	eventsByRound := make(map[int][]models.KillEvent)
+	for _, kill := range kills {
+		eventsByRound[kill.Round] = append(eventsByRound[kill.Round], kill)
+	}
+	for _, events := range eventsByRound {
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Time < events[j].Time
+		})
+	}
+	return eventsByRound
+}
+
+// firstDuel retorna la primera kill entre equipos distintos de la ronda: las team kills
+// y las muertes propias no son un duelo de apertura
+func firstDuel(events []models.KillEvent) (models.KillEvent, bool) {
+	for _, ev := range events {
+		if ev.KillerTeam != "" && ev.KillerTeam != ev.VictimTeam {
+			return ev, true
+		}
+	}
+	return models.KillEvent{}, false
+}
@@ -0,0 +1,52 @@
+package analytics
+
+import "valo-track/internal/models"
+
+// Position es una kill o una muerte de un jugador del stack en coordenadas del juego
+type Position struct {
+	Player string
+	Map    string
+	Side   string // SideAttack, SideDefense o vacío si no se conoce
+	Kill   bool   // true = posición del jugador al hacer la kill, false = donde murió
+	X, Y   int
+}
+
+// Positions retorna las posiciones de kills y muertes de los jugadores del stack en las
+// partidas que traen ubicaciones
+func (as *AnalyticsService) Positions(matches []models.MatchData) []Position {
+	positions := make([]Position, 0)
+
+	for _, match := range matches {
+		rounds := make(map[int]models.RoundData, len(match.Rounds))
+		for _, round := range match.Rounds {
+			rounds[round.Round] = round
+		}
+
+		for _, kill := range match.Kills {
+			round := rounds[kill.Round]
+
+			if kill.KillerName != "" && kill.KillerLocation != nil && kill.KillerTeam != kill.VictimTeam {
+				positions = append(positions, Position{
+					Player: kill.KillerName,
+					Map:    match.Map,
+					Side:   playerSide(round, kill.KillerTeam),
+					Kill:   true,
+					X:      kill.KillerLocation.X,
+					Y:      kill.KillerLocation.Y,
+				})
+			}
+
+			if kill.VictimName != "" && kill.VictimLocation != nil {
+				positions = append(positions, Position{
+					Player: kill.VictimName,
+					Map:    match.Map,
+					Side:   playerSide(round, kill.VictimTeam),
+					X:      kill.VictimLocation.X,
+					Y:      kill.VictimLocation.Y,
+				})
+			}
+		}
+	}
+
+	return positions
+}
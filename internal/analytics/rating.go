@@ -0,0 +1,184 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"valo-track/internal/models"
+)
+
+// Rating compuesto estilo HLTV 2.0. Cada componente se divide por un valor de
+// referencia de un jugador promedio, así que 1.00 es un rendimiento promedio y el
+// rating es el promedio ponderado de los componentes:
+//
+//	kills      kills por ronda                     / 0.70
+//	survival   rondas sobrevividas por ronda       / 0.30
+//	multikill  kills extra en rondas de 2K+ / ronda / 0.12
+//	adr        daño promedio por ronda             / 135
+//	kast       fracción de rondas con KAST         / 0.70
+//	opening    duelos de apertura ganados (suavizado, 0.5 = pareja) / 0.5
+//	clutch     clutches por ronda                  / 0.02
+const (
+	RatingKills     = "kills"
+	RatingSurvival  = "survival"
+	RatingMultiKill = "multikill"
+	RatingADR       = "adr"
+	RatingKAST      = "kast"
+	RatingOpening   = "opening"
+	RatingClutch    = "clutch"
+)
+
+// ratingComponents son los componentes en orden de presentación con su referencia
+var ratingComponents = []struct {
+	Key      string
+	Baseline float64
+}{
+	{RatingKills, 0.70},
+	{RatingSurvival, 0.30},
+	{RatingMultiKill, 0.12},
+	{RatingADR, 135},
+	{RatingKAST, 0.70},
+	{RatingOpening, 0.5},
+	{RatingClutch, 0.02},
+}
+
+// RatingWeights son los pesos de cada componente del rating
+type RatingWeights map[string]float64
+
+// DefaultRatingWeights son los pesos por defecto (suman 1)
+func DefaultRatingWeights() RatingWeights {
+	return RatingWeights{
+		RatingKills:     0.25,
+		RatingSurvival:  0.15,
+		RatingMultiKill: 0.10,
+		RatingADR:       0.20,
+		RatingKAST:      0.15,
+		RatingOpening:   0.10,
+		RatingClutch:    0.05,
+	}
+}
+
+// ParseRatingWeights lee pesos con el formato "kills=0.3,adr=0.2". Los componentes que
+// no aparecen conservan el peso por defecto; un peso 0 desactiva el componente.
+func ParseRatingWeights(spec string) (RatingWeights, error) {
+	weights := DefaultRatingWeights()
+	if strings.TrimSpace(spec) == "" {
+		return weights, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok {
+			return nil, fmt.Errorf("peso de rating mal formado %q: se espera componente=peso", part)
+		}
+		if _, known := weights[key]; !known {
+			return nil, fmt.Errorf("componente de rating desconocido %q (opciones: %s)", key, strings.Join(ratingKeys(), ", "))
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("peso de rating inválido para %s: %q", key, value)
+		}
+		weights[key] = weight
+	}
+
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("los pesos del rating no pueden ser todos 0")
+	}
+
+	return weights, nil
+}
+
+// ratingKeys retorna los nombres de los componentes
+func ratingKeys() []string {
+	keys := make([]string, 0, len(ratingComponents))
+	for _, component := range ratingComponents {
+		keys = append(keys, component.Key)
+	}
+	return keys
+}
+
+// SetRatingWeights cambia los pesos usados para calcular el rating
+func (as *AnalyticsService) SetRatingWeights(weights RatingWeights) {
+	as.ratingWeights = weights
+}
+
+// RatingComponents retorna el valor normalizado (1.00 = promedio) de cada componente
+func RatingComponents(stats *models.PlayerStats) map[string]float64 {
+	rounds := float64(stats.TotalRounds)
+	if rounds == 0 {
+		return nil
+	}
+
+	extraKills := 0
+	for count, occurrences := range stats.MultiKills {
+		if count >= 2 {
+			extraKills += (count - 1) * occurrences
+		}
+	}
+
+	values := map[string]float64{
+		RatingKills:     float64(stats.Kills) / rounds,
+		RatingSurvival:  (rounds - float64(stats.Deaths)) / rounds,
+		RatingMultiKill: float64(extraKills) / rounds,
+		RatingADR:       float64(stats.DamageMade) / rounds,
+		RatingKAST:      float64(stats.KASTRounds) / rounds,
+		RatingOpening:   float64(stats.FirstKills+1) / float64(stats.FirstKills+stats.FirstDeaths+2),
+		RatingClutch:    float64(stats.Clutches) / rounds,
+	}
+
+	for _, component := range ratingComponents {
+		values[component.Key] /= component.Baseline
+	}
+	return values
+}
+
+// Rating calcula el rating compuesto con los pesos del servicio
+func (as *AnalyticsService) Rating(stats *models.PlayerStats) float64 {
+	components := RatingComponents(stats)
+	if components == nil {
+		return 0
+	}
+
+	weights := as.ratingWeights
+	if weights == nil {
+		weights = DefaultRatingWeights()
+	}
+
+	total, weightSum := 0.0, 0.0
+	for _, component := range ratingComponents {
+		weight := weights[component.Key]
+		total += weight * components[component.Key]
+		weightSum += weight
+	}
+	return ratio(total, weightSum)
+}
+
+// PlayerRating es el rating de un jugador en una partida
+type PlayerRating struct {
+	Name   string
+	Rating float64
+}
+
+// MatchRatings retorna el rating de cada jugador del stack en la partida, de mayor a menor.
+// El primero es el MVP y el último el de peor rendimiento.
+func (as *AnalyticsService) MatchRatings(match models.MatchData) []PlayerRating {
+	ratings := make([]PlayerRating, 0, len(match.PlayerData))
+	for name := range match.PlayerData {
+		stats := as.AnalyzeMatches([]models.MatchData{match}, []string{name})
+		ratings = append(ratings, PlayerRating{Name: name, Rating: stats.Rating})
+	}
+
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].Name < ratings[j].Name
+	})
+	return ratings
+}
@@ -3,6 +3,8 @@ package analytics
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 	"valo-track/internal/models"
 )
 
@@ -10,6 +12,13 @@ import (
 type AnalyticsService struct {
 	playerAccountsMap map[string]string
 	tradeWindowMs     int
+	accounts          AccountResolver
+	ratingWeights     RatingWeights
+}
+
+// AccountResolver identifica a una cuenta por PUUID aunque haya cambiado de name#tag
+type AccountResolver interface {
+	Lookup(puuid, name, tag string, timestamp int64) string
 }
 
 // NewAnalyticsService crea un nuevo servicio de análisis
@@ -20,11 +29,19 @@ func NewAnalyticsService(playerAccountsMap map[string]string, tradeWindowMs int)
 	}
 }
 
+// SetAccountResolver hace que los jugadores se identifiquen por PUUID en lugar de name#tag
+func (as *AnalyticsService) SetAccountResolver(accounts AccountResolver) {
+	as.accounts = accounts
+}
+
 // AnalyzeMatches procesa un conjunto de partidas y construye estadísticas consolidadas
 func (as *AnalyticsService) AnalyzeMatches(matches []models.MatchData, playerNames []string) *models.PlayerStats {
 	stats := &models.PlayerStats{
-		Agents:     make(map[string]int),
-		MultiKills: make(map[int]int),
+		Agents:         make(map[string]int),
+		MultiKills:     make(map[int]int),
+		ClutchAttempts: make(map[int]int),
+		ClutchWins:     make(map[int]int),
+		Weapons:        make(map[string]models.WeaponStats),
 	}
 
 	// Inicializar contador de victorias/derrotas
@@ -91,15 +108,62 @@ func (as *AnalyticsService) AnalyzeMatches(matches []models.MatchData, playerNam
 				if clutch, ok := match.Clutches[playerName]; ok {
 					stats.Clutches += clutch
 				}
+				for enemies, count := range match.ClutchAttempts[playerName] {
+					stats.ClutchAttempts[enemies] += count
+				}
+				for enemies, count := range match.ClutchWins[playerName] {
+					stats.ClutchWins[enemies] += count
+				}
+
+				// Economía
+				stats.LoadoutValue += match.LoadoutValue[playerName]
+				stats.CreditsSpent += match.CreditsSpent[playerName]
+				stats.EconomyDamage += match.EconomyDamage[playerName]
+
+				// Armas
+				for weapon, weaponStats := range match.Weapons[playerName] {
+					stats.Weapons[weapon] = addWeaponStats(stats.Weapons[weapon], weaponStats)
+				}
 
 				break
 			}
 		}
 	}
 
+	stats.Rating = as.Rating(stats)
+
 	return stats
 }
 
+// StackPlayerNames retorna los nombres reales del stack, sin duplicados y ordenados
+func (as *AnalyticsService) StackPlayerNames() []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, name := range as.playerAccountsMap {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AnalyzeStack consolida las estadísticas de cada persona del stack que aparece en las
+// partidas. Como PlayerData está indexado por nombre real, se suman todas sus cuentas.
+func (as *AnalyticsService) AnalyzeStack(matches []models.MatchData) []*models.PlayerStats {
+	stackStats := make([]*models.PlayerStats, 0)
+	for _, name := range as.StackPlayerNames() {
+		stats := as.AnalyzeMatches(matches, []string{name})
+		if stats.TotalGames == 0 {
+			continue
+		}
+		stats.Name = name
+		stackStats = append(stackStats, stats)
+	}
+	return stackStats
+}
+
 // ProcessMatchDetails procesa los detalles completos de una partida desde la API
 func (as *AnalyticsService) ProcessMatchDetails(apiMatch interface{}, minStackPlayers int) *models.MatchData {
 	// Type assert con seguridad
@@ -108,39 +172,51 @@ func (as *AnalyticsService) ProcessMatchDetails(apiMatch interface{}, minStackPl
 		return nil
 	}
 
+	// Identificar a los jugadores del stack (por PUUID si hay registro de cuentas)
+	timestamp := as.MatchTimestamp(fullMatch)
+	stackNamesByPUUID := as.BuildStackNamesByPUUID(fullMatch.Data.Players, timestamp)
+
 	// Verificar si la partida tiene suficientes jugadores del stack
-	stackPlayers := as.GetStackPlayers(fullMatch.Data.Players)
+	stackPlayers := as.GetStackPlayers(fullMatch.Data.Players, stackNamesByPUUID)
 	if len(stackPlayers) < minStackPlayers {
 		return nil // No incluir esta partida
 	}
 
 	// Construir datos de la partida
 	match := &models.MatchData{
-		MatchID:      fullMatch.Data.ID,
-		Map:          fullMatch.Data.Metadata.Map.Name,
-		Mode:         fullMatch.Data.Metadata.Queue.ID,
-		PlayerData:   make(map[string]models.PlayerMatchStats),
-		FirstKills:   make(map[string]int),
-		FirstDeaths:  make(map[string]int),
-		KASTRounds:   make(map[string]int),
-		PlayerTeams:  make(map[string]string),
-		AttackKills:   make(map[string]int),
-		AttackDeaths:  make(map[string]int),
-		AttackDamage:  make(map[string]int),
-		AttackRounds:  make(map[string]int),
-		DefenseKills:  make(map[string]int),
-		DefenseDeaths: make(map[string]int),
-		DefenseDamage: make(map[string]int),
-		DefenseRounds: make(map[string]int),
-		MultiKills:    make(map[string]map[int]int),
-		Clutches:      make(map[string]int),
-		RoundsPlayed:  as.CalculateRoundsPlayed(fullMatch.Data.Rounds),
-		Timestamp:     fullMatch.Data.Metadata.GameStart,
+		MatchID:        as.MatchID(fullMatch),
+		Map:            fullMatch.Data.Metadata.Map.Name,
+		Mode:           fullMatch.Data.Metadata.Queue.ID,
+		PlayerData:     make(map[string]models.PlayerMatchStats),
+		FirstKills:     make(map[string]int),
+		FirstDeaths:    make(map[string]int),
+		KASTRounds:     make(map[string]int),
+		PlayerTeams:    make(map[string]string),
+		AttackKills:    make(map[string]int),
+		AttackDeaths:   make(map[string]int),
+		AttackDamage:   make(map[string]int),
+		AttackRounds:   make(map[string]int),
+		DefenseKills:   make(map[string]int),
+		DefenseDeaths:  make(map[string]int),
+		DefenseDamage:  make(map[string]int),
+		DefenseRounds:  make(map[string]int),
+		MultiKills:     make(map[string]map[int]int),
+		Clutches:       make(map[string]int),
+		ClutchAttempts: make(map[string]map[int]int),
+		ClutchWins:     make(map[string]map[int]int),
+		LoadoutValue:   make(map[string]int),
+		CreditsSpent:   make(map[string]int),
+		EconomyDamage:  make(map[string]int),
+		Weapons:        make(map[string]map[string]models.WeaponStats),
+		RoundsPlayed:   as.CalculateRoundsPlayed(fullMatch.Data.Rounds),
+		Timestamp:      timestamp,
+		Season:         fullMatch.Data.Metadata.Season.Short,
+		SeasonID:       fullMatch.Data.Metadata.Season.ID,
 	}
 
 	// Procesar stats de jugadores
 	for _, player := range fullMatch.Data.Players {
-		playerName := as.GetPlayerName(player.Name, player.Tag)
+		playerName := stackNamesByPUUID[player.PUUID]
 		if playerName == "" {
 			continue // No es un jugador del stack
 		}
@@ -166,8 +242,7 @@ func (as *AnalyticsService) ProcessMatchDetails(apiMatch interface{}, minStackPl
 		hasStackPlayer := false
 		for _, player := range fullMatch.Data.Players {
 			if player.TeamID == team.TeamID {
-				playerName := as.GetPlayerName(player.Name, player.Tag)
-				if playerName != "" {
+				if stackNamesByPUUID[player.PUUID] != "" {
 					hasStackPlayer = true
 					break
 				}
@@ -183,7 +258,6 @@ func (as *AnalyticsService) ProcessMatchDetails(apiMatch interface{}, minStackPl
 	teamMembers := as.BuildTeamMembers(fullMatch.Data.Players)
 
 	// Procesar eventos de muerte y calcular stats avanzados
-	stackNamesByPUUID := as.BuildStackNamesByPUUID(fullMatch.Data.Players)
 	eventsByRound := as.BuildKillEvents(fullMatch.Data.Kills, stackNamesByPUUID)
 
 	// Calcular trades
@@ -195,10 +269,14 @@ func (as *AnalyticsService) ProcessMatchDetails(apiMatch interface{}, minStackPl
 		match.MultiKills[name] = mkData
 	}
 
-	// Calcular clutches
-	clutches := as.ComputeClutches(eventsByRound, teamMembers)
-	for name, count := range clutches {
-		match.Clutches[name] = count
+	// Calcular clutches (intentos y victorias por 1vN)
+	clutchAttempts, clutchWins := as.ComputeClutches(eventsByRound, teamMembers, stackNamesByPUUID, as.BuildRoundWinners(fullMatch.Data.Rounds))
+	for name, attempts := range clutchAttempts {
+		match.ClutchAttempts[name] = attempts
+		match.ClutchWins[name] = clutchWins[name]
+		for _, count := range clutchWins[name] {
+			match.Clutches[name] += count
+		}
 	}
 
 	// Calcular First Kills/Deaths y KAST
@@ -208,15 +286,46 @@ func (as *AnalyticsService) ProcessMatchDetails(apiMatch interface{}, minStackPl
 	// Calcular stats por lado (ataque/defensa)
 	as.CalculateSideStats(fullMatch.Data.Rounds, fullMatch.Data.Kills, match, stackNamesByPUUID)
 
+	// Calcular economía por jugador
+	as.CalculateEconomy(fullMatch.Data.Rounds, match, stackNamesByPUUID)
+
+	// Calcular stats por arma
+	as.CalculateWeapons(fullMatch.Data.Rounds, fullMatch.Data.Kills, match, stackNamesByPUUID)
+
+	// Guardar detalle por ronda y eventos de muerte
+	match.Rounds = as.BuildRoundData(fullMatch.Data.Rounds, stackNamesByPUUID)
+	match.Kills = as.FlattenKillEvents(eventsByRound)
+
 	return match
 }
 
+// MatchID obtiene el ID de la partida (v4 lo expone en metadata.match_id)
+func (as *AnalyticsService) MatchID(fullMatch *models.V4MatchResponse) string {
+	if fullMatch.Data.ID != "" {
+		return fullMatch.Data.ID
+	}
+	return fullMatch.Data.Metadata.MatchID
+}
+
+// MatchTimestamp obtiene el inicio de la partida en segundos Unix
+func (as *AnalyticsService) MatchTimestamp(fullMatch *models.V4MatchResponse) int64 {
+	if fullMatch.Data.Metadata.GameStart > 0 {
+		return fullMatch.Data.Metadata.GameStart
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, fullMatch.Data.Metadata.StartedAt)
+	if err != nil {
+		return 0
+	}
+	return startedAt.Unix()
+}
+
 // GetStackPlayers retorna los jugadores del stack presentes en la partida
-func (as *AnalyticsService) GetStackPlayers(players []models.V4MatchPlayer) map[string]models.PlayerMatchStats {
+func (as *AnalyticsService) GetStackPlayers(players []models.V4MatchPlayer, stackNamesByPUUID map[string]string) map[string]models.PlayerMatchStats {
 	stackPlayers := make(map[string]models.PlayerMatchStats)
 
 	for _, player := range players {
-		playerName := as.GetPlayerName(player.Name, player.Tag)
+		playerName := stackNamesByPUUID[player.PUUID]
 		if playerName != "" {
 			stackPlayers[playerName] = models.PlayerMatchStats{
 				Kills:          player.Stats.Kills,
@@ -237,15 +346,24 @@ func (as *AnalyticsService) GetStackPlayers(players []models.V4MatchPlayer) map[
 	return stackPlayers
 }
 
-// GetPlayerName mapea una cuenta de Valorant a un nombre real
+// GetPlayerName mapea una cuenta de Valorant a un nombre real. Las claves del mapeo
+// están en minúsculas (config.BuildAccountsMap): Riot no distingue mayúsculas.
 func (as *AnalyticsService) GetPlayerName(name, tag string) string {
-	key := fmt.Sprintf("%s#%s", name, tag)
+	key := strings.ToLower(fmt.Sprintf("%s#%s", name, tag))
 	if player, ok := as.playerAccountsMap[key]; ok {
 		return player
 	}
 	return ""
 }
 
+// ResolvePlayer retorna el nombre real del dueño de la cuenta, o vacío si no es del stack
+func (as *AnalyticsService) ResolvePlayer(player models.V4MatchPlayer, timestamp int64) string {
+	if as.accounts != nil {
+		return as.accounts.Lookup(player.PUUID, player.Name, player.Tag, timestamp)
+	}
+	return as.GetPlayerName(player.Name, player.Tag)
+}
+
 // BuildTeamMembers construye un mapeo de equipos con sus miembros (PUUIDs)
 func (as *AnalyticsService) BuildTeamMembers(players []models.V4MatchPlayer) map[string]map[string]struct{} {
 	teams := make(map[string]map[string]struct{})
@@ -259,10 +377,10 @@ func (as *AnalyticsService) BuildTeamMembers(players []models.V4MatchPlayer) map
 }
 
 // BuildStackNamesByPUUID crea un mapeo de PUUID a nombres de jugadores del stack
-func (as *AnalyticsService) BuildStackNamesByPUUID(players []models.V4MatchPlayer) map[string]string {
+func (as *AnalyticsService) BuildStackNamesByPUUID(players []models.V4MatchPlayer, timestamp int64) map[string]string {
 	mapping := make(map[string]string)
 	for _, player := range players {
-		playerName := as.GetPlayerName(player.Name, player.Tag)
+		playerName := as.ResolvePlayer(player, timestamp)
 		if playerName != "" {
 			mapping[player.PUUID] = playerName
 		}
@@ -284,6 +402,16 @@ func (as *AnalyticsService) BuildKillEvents(kills []models.V4KillEventResponse,
 			VictimTeam:  kill.Victim.Team,
 			KillerPUUID: kill.Killer.PUUID,
 			VictimPUUID: kill.Victim.PUUID,
+			Weapon:      KillWeapon(kill),
+		}
+
+		ke.VictimLocation = kill.Location
+		for _, position := range kill.PlayerLocations {
+			if position.Player.PUUID == kill.Killer.PUUID {
+				location := position.Location
+				ke.KillerLocation = &location
+				break
+			}
 		}
 
 		for _, assistant := range kill.Assistants {
@@ -310,6 +438,28 @@ func (as *AnalyticsService) BuildKillEvents(kills []models.V4KillEventResponse,
 func (as *AnalyticsService) ComputeTrades(eventsByRound map[int][]models.KillEvent) map[int]map[string]bool {
 	trades := make(map[int]map[string]bool)
 
+	for _, trade := range as.FindTrades(eventsByRound) {
+		if _, ok := trades[trade.Round]; !ok {
+			trades[trade.Round] = make(map[string]bool)
+		}
+		trades[trade.Round][trade.Victim] = true
+	}
+
+	return trades
+}
+
+// Trade es la muerte de un jugador del stack vengada por un compañero dentro de la ventana
+type Trade struct {
+	Round   int
+	Victim  string
+	Trader  string // Vacío si tradeó alguien que no es del stack
+	DelayMs int    // Tiempo entre la muerte y el trade
+}
+
+// FindTrades retorna cada muerte del stack que fue tradeada y quién la tradeó
+func (as *AnalyticsService) FindTrades(eventsByRound map[int][]models.KillEvent) []Trade {
+	trades := make([]Trade, 0)
+
 	for round, events := range eventsByRound {
 		for i, ev := range events {
 			if ev.VictimName == "" {
@@ -320,10 +470,12 @@ func (as *AnalyticsService) ComputeTrades(eventsByRound map[int][]models.KillEve
 			for j := i + 1; j < len(events) && events[j].Time <= window; j++ {
 				next := events[j]
 				if next.VictimPUUID == ev.KillerPUUID && next.KillerTeam == ev.VictimTeam {
-					if _, ok := trades[round]; !ok {
-						trades[round] = make(map[string]bool)
-					}
-					trades[round][ev.VictimName] = true
+					trades = append(trades, Trade{
+						Round:   round,
+						Victim:  ev.VictimName,
+						Trader:  next.KillerName,
+						DelayMs: next.Time - ev.Time,
+					})
 					break
 				}
 			}
@@ -360,29 +512,64 @@ func (as *AnalyticsService) ComputeMultiKills(eventsByRound map[int][]models.Kil
 	return multi
 }
 
-// ComputeClutches detecta clutches (el último jugador del equipo obtiene la ronda)
-func (as *AnalyticsService) ComputeClutches(eventsByRound map[int][]models.KillEvent, teamMembers map[string]map[string]struct{}) map[string]int {
-	clutches := make(map[string]int)
+// ComputeClutches registra cada situación de clutch del stack: un jugador queda último
+// vivo de su equipo contra N rivales (1v1 a 1v5). El resultado sale del ganador de la
+// ronda, así que cuentan también los clutches ganados por defuse o detonación.
+// Retorna intentos y victorias por jugador y cantidad de rivales.
+func (as *AnalyticsService) ComputeClutches(eventsByRound map[int][]models.KillEvent, teamMembers map[string]map[string]struct{}, stackNamesByPUUID map[string]string, winners map[int]string) (map[string]map[int]int, map[string]map[int]int) {
+	attempts := make(map[string]map[int]int)
+	wins := make(map[string]map[int]int)
 
-	for _, events := range eventsByRound {
+	for round, events := range eventsByRound {
 		alive := as.CloneTeamMembers(teamMembers)
-		for _, ev := range events {
-			teamAliveBefore := len(alive[ev.KillerTeam])
-			enemyAliveBefore := len(alive[ev.VictimTeam])
+		inClutch := make(map[string]bool) // Equipos que ya tienen un jugador en clutch esta ronda
 
+		for _, ev := range events {
 			delete(alive[ev.VictimTeam], ev.VictimPUUID)
 
-			if ev.KillerName == "" {
+			team := ev.VictimTeam
+			if inClutch[team] || len(alive[team]) != 1 {
 				continue
 			}
+			inClutch[team] = true
 
-			if enemyAliveBefore > 0 && len(alive[ev.VictimTeam]) == 0 && teamAliveBefore == 1 {
-				clutches[ev.KillerName]++
+			enemies := 0
+			for otherTeam, members := range alive {
+				if otherTeam != team {
+					enemies += len(members)
+				}
+			}
+			if enemies == 0 {
+				continue // La ronda ya terminó
+			}
+
+			for puuid := range alive[team] {
+				name := stackNamesByPUUID[puuid]
+				if name == "" {
+					continue
+				}
+				if _, ok := attempts[name]; !ok {
+					attempts[name] = make(map[int]int)
+					wins[name] = make(map[int]int)
+				}
+				attempts[name][enemies]++
+				if winners[round] == team {
+					wins[name][enemies]++
+				}
 			}
 		}
 	}
 
-	return clutches
+	return attempts, wins
+}
+
+// BuildRoundWinners retorna el equipo ganador de cada ronda
+func (as *AnalyticsService) BuildRoundWinners(rounds []models.V4Round) map[int]string {
+	winners := make(map[int]string, len(rounds))
+	for _, round := range rounds {
+		winners[round.ID] = round.WinningTeam
+	}
+	return winners
 }
 
 // CloneTeamMembers crea una copia profunda del mapeo de equipos
@@ -400,12 +587,11 @@ func (as *AnalyticsService) CloneTeamMembers(src map[string]map[string]struct{})
 // CalculateFirstKillsAndDeaths calcula First Kills y First Deaths
 func (as *AnalyticsService) CalculateFirstKillsAndDeaths(eventsByRound map[int][]models.KillEvent, match *models.MatchData) {
 	for _, events := range eventsByRound {
-		if len(events) == 0 {
+		// First kill es el primer duelo entre equipos (igual que en las aperturas)
+		firstKill, ok := firstDuel(events)
+		if !ok {
 			continue
 		}
-
-		// First kill es el primer evento
-		firstKill := events[0]
 		if firstKill.KillerName != "" {
 			match.FirstKills[firstKill.KillerName]++
 		}
@@ -472,7 +658,7 @@ func (as *AnalyticsService) CalculateSideStats(rounds []models.V4Round, kills []
 		return
 	}
 
-	secondTeam := as.PickSecondTeam(match.PlayerTeams)
+	secondTeam := as.PickSecondTeam(rounds, firstAttackTeam)
 	if secondTeam == "" {
 		return
 	}
@@ -485,18 +671,24 @@ func (as *AnalyticsService) CalculateSideStats(rounds []models.V4Round, kills []
 		victimName := stackNamesByPUUID[kill.Victim.PUUID]
 
 		attackingTeam := attackingByRound[kill.Round]
-		if killerName == "" || victimName == "" {
+		if attackingTeam == "" {
 			continue
 		}
 
-		if kill.Killer.Team == attackingTeam {
-			// Killer estaba atacando
-			match.AttackKills[killerName]++
-			match.DefenseDeaths[victimName]++
-		} else {
-			// Killer estaba defendiendo
-			match.DefenseKills[killerName]++
-			match.AttackDeaths[victimName]++
+		// Killer y víctima se cuentan por separado: alcanza con que uno sea del stack
+		if killerName != "" {
+			if kill.Killer.Team == attackingTeam {
+				match.AttackKills[killerName]++
+			} else {
+				match.DefenseKills[killerName]++
+			}
+		}
+		if victimName != "" {
+			if kill.Victim.Team == attackingTeam {
+				match.AttackDeaths[victimName]++
+			} else {
+				match.DefenseDeaths[victimName]++
+			}
 		}
 	}
 
@@ -521,30 +713,30 @@ func (as *AnalyticsService) CalculateSideStats(rounds []models.V4Round, kills []
 	}
 }
 
-// InferInitialAttackingTeam detecta qué equipo atacaba primero
+// InferInitialAttackingTeam detecta qué equipo atacaba primero a partir del primer plant,
+// teniendo en cuenta si ese plant fue en la segunda mitad u overtime
 func (as *AnalyticsService) InferInitialAttackingTeam(rounds []models.V4Round) string {
-	for _, round := range rounds {
-		if round.Plant != nil && round.Plant.Player.Team != "" {
-			return round.Plant.Player.Team
+	for idx, round := range rounds {
+		if round.Plant == nil || round.Plant.Player.Team == "" {
+			continue
+		}
+		planter := round.Plant.Player.Team
+		if as.SideByRoundIndex(idx, "first", "second") == "first" {
+			return planter
 		}
+		return as.PickSecondTeam(rounds, planter)
 	}
 	return ""
 }
 
-// PickSecondTeam selecciona el equipo que no es el primero
-func (as *AnalyticsService) PickSecondTeam(playerTeams map[string]string) string {
-	seenTeams := make(map[string]bool)
-	var firstTeam string
-	for _, team := range playerTeams {
-		if firstTeam == "" {
-			firstTeam = team
-		}
-		seenTeams[team] = true
-	}
-
-	for team := range seenTeams {
-		if team != firstTeam {
-			return team
+// PickSecondTeam selecciona el equipo que no es el primero, mirando a todos los
+// jugadores de las rondas (no solo a los del stack)
+func (as *AnalyticsService) PickSecondTeam(rounds []models.V4Round, firstTeam string) string {
+	for _, round := range rounds {
+		for _, stat := range round.Stats {
+			if stat.Player.Team != "" && stat.Player.Team != firstTeam {
+				return stat.Player.Team
+			}
 		}
 	}
 	return ""
@@ -584,6 +776,55 @@ func (as *AnalyticsService) SideByRoundIndex(idx int, firstAttack, secondTeam st
 	}
 }
 
+// BuildRoundData resume cada ronda con su ganador y el equipo atacante
+func (as *AnalyticsService) BuildRoundData(rounds []models.V4Round, stackNamesByPUUID map[string]string) []models.RoundData {
+	firstAttackTeam := as.InferInitialAttackingTeam(rounds)
+	secondTeam := as.PickSecondTeam(rounds, firstAttackTeam)
+
+	attackingByRound := as.BuildAttackingTeamByRound(rounds, firstAttackTeam, secondTeam)
+
+	data := make([]models.RoundData, 0, len(rounds))
+	for idx, round := range rounds {
+		roundData := models.RoundData{
+			Round:         round.ID,
+			WinningTeam:   round.WinningTeam,
+			AttackingTeam: attackingByRound[round.ID],
+			Result:        round.Result,
+			Economy:       as.BuildTeamEconomy(idx, round),
+		}
+
+		if round.Plant != nil {
+			roundData.PlantSite = round.Plant.Site
+			roundData.PlantTimeMs = round.Plant.RoundTimeInMs
+			roundData.Planter = stackNamesByPUUID[round.Plant.Player.PUUID]
+		}
+		if round.Defuse != nil {
+			roundData.Defused = true
+			roundData.Defuser = stackNamesByPUUID[round.Defuse.Player.PUUID]
+		}
+
+		data = append(data, roundData)
+	}
+
+	return data
+}
+
+// FlattenKillEvents devuelve los eventos de muerte ordenados por ronda y tiempo
+func (as *AnalyticsService) FlattenKillEvents(eventsByRound map[int][]models.KillEvent) []models.KillEvent {
+	rounds := make([]int, 0, len(eventsByRound))
+	for round := range eventsByRound {
+		rounds = append(rounds, round)
+	}
+	sort.Ints(rounds)
+
+	kills := make([]models.KillEvent, 0)
+	for _, round := range rounds {
+		kills = append(kills, eventsByRound[round]...)
+	}
+
+	return kills
+}
+
 // CalculateRoundsPlayed calcula el número de rondas jugadas
 func (as *AnalyticsService) CalculateRoundsPlayed(rounds []models.V4Round) int {
 	return len(rounds)
@@ -0,0 +1,188 @@
+package analytics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"valo-track/internal/models"
+)
+
+// clutchTeams arma dos equipos de 5 (r1..r5 en Red, b1..b5 en Blue)
+func clutchTeams() map[string]map[string]struct{} {
+	teams := map[string]map[string]struct{}{"Red": {}, "Blue": {}}
+	for _, n := range "12345" {
+		teams["Red"]["r"+string(n)] = struct{}{}
+		teams["Blue"]["b"+string(n)] = struct{}{}
+	}
+	return teams
+}
+
+// clutchKill arma una kill entre PUUIDs de clutchTeams (el equipo sale del prefijo)
+func clutchKill(killer, victim string) models.KillEvent {
+	team := func(puuid string) string {
+		if strings.HasPrefix(puuid, "r") {
+			return "Red"
+		}
+		return "Blue"
+	}
+	return models.KillEvent{
+		KillerPUUID: killer,
+		VictimPUUID: victim,
+		KillerTeam:  team(killer),
+		VictimTeam:  team(victim),
+	}
+}
+
+func TestComputeClutches(t *testing.T) {
+	as := NewAnalyticsService(nil, 5000)
+
+	// Todo el equipo Red es del stack; de Blue solo b5
+	stack := map[string]string{"r1": "R1", "r2": "R2", "r3": "R3", "r4": "R4", "r5": "R5", "b5": "B5"}
+
+	// redDown mata a r2..r5 después de matar a los primeros blueDown de Blue: r1 queda 1v(5-blueDown)
+	redDown := func(blueDown int) []models.KillEvent {
+		var events []models.KillEvent
+		for i := 1; i <= blueDown; i++ {
+			events = append(events, clutchKill("r2", "b"+string(rune('0'+i))))
+		}
+		for _, victim := range []string{"r2", "r3", "r4", "r5"} {
+			events = append(events, clutchKill("b5", victim))
+		}
+		return events
+	}
+
+	tests := []struct {
+		name      string
+		events    map[int][]models.KillEvent
+		winners   map[int]string
+		wantTries map[string]map[int]int
+		wantWins  map[string]map[int]int
+	}{
+		{
+			name:      "1v5",
+			events:    map[int][]models.KillEvent{0: redDown(0)},
+			winners:   map[int]string{0: "Blue"},
+			wantTries: map[string]map[int]int{"R1": {5: 1}},
+			wantWins:  map[string]map[int]int{"R1": {}},
+		},
+		{
+			name:      "1v3",
+			events:    map[int][]models.KillEvent{0: redDown(2)},
+			winners:   map[int]string{0: "Blue"},
+			wantTries: map[string]map[int]int{"R1": {3: 1}},
+			wantWins:  map[string]map[int]int{"R1": {}},
+		},
+		{
+			name: "1v1 ganado con la última kill",
+			events: map[int][]models.KillEvent{0: append(redDown(4),
+				clutchKill("r1", "b5"))},
+			winners: map[int]string{0: "Red"},
+			// b5 quedó solo primero (1v5) y después r1 (1v1)
+			wantTries: map[string]map[int]int{"R1": {1: 1}, "B5": {5: 1}},
+			wantWins:  map[string]map[int]int{"R1": {1: 1}, "B5": {}},
+		},
+		{
+			name: "un solo intento por equipo y ronda aunque caigan más rivales",
+			events: map[int][]models.KillEvent{0: append(redDown(1),
+				clutchKill("r1", "b2"), clutchKill("r1", "b3"), clutchKill("r1", "b4"))},
+			winners:   map[int]string{0: "Red"},
+			wantTries: map[string]map[int]int{"R1": {4: 1}, "B5": {1: 1}},
+			wantWins:  map[string]map[int]int{"R1": {4: 1}, "B5": {}},
+		},
+		{
+			name:      "ganado por defuse sin más kills",
+			events:    map[int][]models.KillEvent{0: redDown(3)},
+			winners:   map[int]string{0: "Red"},
+			wantTries: map[string]map[int]int{"R1": {2: 1}},
+			wantWins:  map[string]map[int]int{"R1": {2: 1}},
+		},
+		{
+			name:      "perdido por detonación sin morir",
+			events:    map[int][]models.KillEvent{0: redDown(3)},
+			winners:   map[int]string{0: "Blue"},
+			wantTries: map[string]map[int]int{"R1": {2: 1}},
+			wantWins:  map[string]map[int]int{"R1": {}},
+		},
+		{
+			// Red queda 1v2; r1 mata a b4 y Blue también queda con uno: b5 entra en 1v1,
+			// pero el intento de r1 sigue siendo el 1v2 original
+			name: "los dos equipos quedan con uno",
+			events: map[int][]models.KillEvent{0: append(redDown(3),
+				clutchKill("r1", "b4"))},
+			winners:   map[int]string{0: "Blue"},
+			wantTries: map[string]map[int]int{"R1": {2: 1}, "B5": {1: 1}},
+			wantWins:  map[string]map[int]int{"R1": {}, "B5": {1: 1}},
+		},
+		{
+			name: "cada ronda cuenta por separado",
+			events: map[int][]models.KillEvent{
+				0: redDown(2),
+				1: redDown(2),
+				2: redDown(4),
+			},
+			winners:   map[int]string{0: "Red", 1: "Blue", 2: "Red"},
+			wantTries: map[string]map[int]int{"R1": {3: 2, 1: 1}, "B5": {5: 1}},
+			wantWins:  map[string]map[int]int{"R1": {3: 1, 1: 1}, "B5": {}},
+		},
+		{
+			name: "sin clutch si nadie queda solo",
+			events: map[int][]models.KillEvent{0: {
+				clutchKill("r1", "b1"), clutchKill("r1", "b2"), clutchKill("b3", "r2"),
+			}},
+			winners:   map[int]string{0: "Red"},
+			wantTries: map[string]map[int]int{},
+			wantWins:  map[string]map[int]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tries, wins := as.ComputeClutches(tt.events, clutchTeams(), stack, tt.winners)
+			if !reflect.DeepEqual(tries, tt.wantTries) {
+				t.Errorf("intentos = %v, se esperaba %v", tries, tt.wantTries)
+			}
+			if !reflect.DeepEqual(wins, tt.wantWins) {
+				t.Errorf("victorias = %v, se esperaba %v", wins, tt.wantWins)
+			}
+		})
+	}
+}
+
+func TestGetPlayerNameIgnoresCase(t *testing.T) {
+	as := NewAnalyticsService(map[string]string{"rosarino#carc": "Rosarino"}, 5000)
+
+	for _, account := range [][2]string{{"Rosarino", "CARC"}, {"ROSARINO", "carc"}, {"rosarino", "Carc"}} {
+		if got := as.GetPlayerName(account[0], account[1]); got != "Rosarino" {
+			t.Errorf("GetPlayerName(%s, %s) = %q, se esperaba Rosarino", account[0], account[1], got)
+		}
+	}
+	if got := as.GetPlayerName("Rosarino", "CARD"); got != "" {
+		t.Errorf("GetPlayerName de una cuenta ajena = %q, se esperaba vacío", got)
+	}
+}
+
+func TestCalculateFirstKillsAndDeathsSkipsTeamKills(t *testing.T) {
+	as := NewAnalyticsService(nil, 5000)
+
+	named := func(killer, victim, killerName, victimName string) models.KillEvent {
+		ev := clutchKill(killer, victim)
+		ev.KillerName, ev.VictimName = killerName, victimName
+		return ev
+	}
+	eventsByRound := map[int][]models.KillEvent{
+		// La ronda arranca con una team kill de R1 sobre R2: el primer duelo es el de b1
+		0: {named("r1", "r2", "R1", "R2"), named("b1", "r3", "", "R3"), named("r1", "b1", "R1", "")},
+		1: {named("r2", "b2", "R2", "")},
+		2: {named("r4", "r5", "R4", "R5")}, // Solo team kills: no hay primer duelo
+	}
+
+	match := &models.MatchData{FirstKills: map[string]int{}, FirstDeaths: map[string]int{}}
+	as.CalculateFirstKillsAndDeaths(eventsByRound, match)
+
+	if want := map[string]int{"R2": 1}; !reflect.DeepEqual(match.FirstKills, want) {
+		t.Errorf("FirstKills = %v, se esperaba %v", match.FirstKills, want)
+	}
+	if want := map[string]int{"R3": 1}; !reflect.DeepEqual(match.FirstDeaths, want) {
+		t.Errorf("FirstDeaths = %v, se esperaba %v", match.FirstDeaths, want)
+	}
+}
@@ -0,0 +1,163 @@
+package analytics
+
+import (
+	"sort"
+	"valo-track/internal/models"
+)
+
+// SpikeStats resume plants, defuses y post-plant del stack en un mapa (o en total)
+type SpikeStats struct {
+	Map            string
+	AttackRounds   int
+	Plants         int // Rondas de ataque en las que el stack plantó
+	PlantTimeTotal int // Suma de los ms hasta el plant, para el promedio
+	PostPlant      RoundRecord
+	Sites          map[string]*RoundRecord // Rondas con plant del stack, por sitio
+	DefenseRounds  int
+	Retakes        RoundRecord // Rondas de defensa en las que el rival plantó
+	Defuses        int
+}
+
+// newSpikeStats crea los stats vacíos de un mapa
+func newSpikeStats(mapName string) *SpikeStats {
+	return &SpikeStats{
+		Map:       mapName,
+		PostPlant: RoundRecord{Name: "post-plant"},
+		Retakes:   RoundRecord{Name: "retake"},
+		Sites:     make(map[string]*RoundRecord),
+	}
+}
+
+// PlantRate retorna el porcentaje de rondas de ataque con plant
+func (ss *SpikeStats) PlantRate() float64 {
+	return ratio(float64(ss.Plants)*100, float64(ss.AttackRounds))
+}
+
+// AveragePlantTime retorna el tiempo promedio hasta el plant, en segundos
+func (ss *SpikeStats) AveragePlantTime() float64 {
+	return ratio(float64(ss.PlantTimeTotal)/1000, float64(ss.Plants))
+}
+
+// SiteRecords retorna los registros por sitio ordenados (A, B, C)
+func (ss *SpikeStats) SiteRecords() []RoundRecord {
+	records := make([]RoundRecord, 0, len(ss.Sites))
+	for _, record := range ss.Sites {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+	return records
+}
+
+// addRound suma una ronda desde el lado del stack
+func (ss *SpikeStats) addRound(round models.RoundData, stackTeam string) {
+	won := round.WinningTeam == stackTeam
+	planted := round.PlantSite != ""
+
+	if round.AttackingTeam == stackTeam {
+		ss.AttackRounds++
+		if !planted {
+			return
+		}
+		ss.Plants++
+		ss.PlantTimeTotal += round.PlantTimeMs
+		ss.PostPlant.Add(won)
+
+		site, ok := ss.Sites[round.PlantSite]
+		if !ok {
+			site = &RoundRecord{Name: round.PlantSite}
+			ss.Sites[round.PlantSite] = site
+		}
+		site.Add(won)
+		return
+	}
+
+	ss.DefenseRounds++
+	if planted {
+		ss.Retakes.Add(won)
+		if round.Defused && round.WinningTeam == stackTeam {
+			ss.Defuses++
+		}
+	}
+}
+
+// NamedCount asocia un nombre con una cantidad
+type NamedCount struct {
+	Name  string
+	Count int
+}
+
+// SpikeReport contiene los stats de spike del stack por mapa y quién planta y defusea
+type SpikeReport struct {
+	Overall  *SpikeStats
+	Maps     []*SpikeStats // Ordenados por nombre de mapa
+	Planters []NamedCount  // De mayor a menor
+	Defusers []NamedCount
+}
+
+// AnalyzeSpike calcula plant rate, win rate por sitio, post-plant y retakes por mapa.
+// Las rondas sin lado conocido se ignoran.
+func (as *AnalyticsService) AnalyzeSpike(matches []models.MatchData) *SpikeReport {
+	overall := newSpikeStats("Total")
+	byMap := make(map[string]*SpikeStats)
+	planters := make(map[string]int)
+	defusers := make(map[string]int)
+
+	for _, match := range matches {
+		stackTeam := StackTeam(&match)
+		if stackTeam == "" {
+			continue
+		}
+
+		mapStats, ok := byMap[match.Map]
+		if !ok {
+			mapStats = newSpikeStats(match.Map)
+			byMap[match.Map] = mapStats
+		}
+
+		for _, round := range match.Rounds {
+			if round.AttackingTeam == "" {
+				continue
+			}
+			overall.addRound(round, stackTeam)
+			mapStats.addRound(round, stackTeam)
+
+			if round.Planter != "" {
+				planters[round.Planter]++
+			}
+			if round.Defuser != "" {
+				defusers[round.Defuser]++
+			}
+		}
+	}
+
+	report := &SpikeReport{
+		Overall:  overall,
+		Planters: sortedCounts(planters),
+		Defusers: sortedCounts(defusers),
+	}
+	for _, mapStats := range byMap {
+		report.Maps = append(report.Maps, mapStats)
+	}
+	sort.Slice(report.Maps, func(i, j int) bool {
+		return report.Maps[i].Map < report.Maps[j].Map
+	})
+
+	return report
+}
+
+// sortedCounts ordena un conteo de mayor a menor (y por nombre si empatan)
+func sortedCounts(counts map[string]int) []NamedCount {
+	sorted := make([]NamedCount, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, NamedCount{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"valo-track/internal/models"
+)
+
+// Lineup son las partidas jugadas juntas por un grupo de jugadores del stack
+type Lineup struct {
+	Players     []string // Ordenados por nombre
+	Games       int
+	Wins        int
+	RatingTotal float64 // Suma del rating promedio del grupo en cada partida
+}
+
+// Label retorna los jugadores del grupo separados por " + "
+func (l *Lineup) Label() string {
+	return strings.Join(l.Players, " + ")
+}
+
+// WinRate retorna el porcentaje de partidas ganadas juntos
+func (l *Lineup) WinRate() float64 {
+	return ratio(float64(l.Wins)*100, float64(l.Games))
+}
+
+// AverageRating retorna el rating promedio del grupo cuando juegan juntos
+func (l *Lineup) AverageRating() float64 {
+	return ratio(l.RatingTotal, float64(l.Games))
+}
+
+// Partner retorna el otro jugador de una dupla
+func (l *Lineup) Partner(player string) string {
+	for _, name := range l.Players {
+		if name != player {
+			return name
+		}
+	}
+	return ""
+}
+
+// Partners son el mejor y el peor compañero de dupla de un jugador (nil si no hay
+// duplas con suficientes partidas)
+type Partners struct {
+	Player string
+	Best   *Lineup
+	Worst  *Lineup
+}
+
+// SynergyReport contiene el rendimiento de cada dupla, trío y lineup completo del stack
+type SynergyReport struct {
+	MinGames int
+	Duos     []*Lineup // Solo los grupos con MinGames o más, de mayor a menor win rate y rating
+	Trios    []*Lineup
+	Fives    []*Lineup
+	Partners []Partners // Ordenados por jugador
+}
+
+// AnalyzeSynergy calcula win rate y rating promedio de cada dupla, trío y lineup de cinco
+// a partir de los jugadores del stack de cada partida. Los grupos con menos de minGames
+// partidas se descartan.
+func (as *AnalyticsService) AnalyzeSynergy(matches []models.MatchData, minGames int) *SynergyReport {
+	duos := make(map[string]*Lineup)
+	trios := make(map[string]*Lineup)
+	fives := make(map[string]*Lineup)
+
+	for _, match := range matches {
+		ratings := make(map[string]float64, len(match.PlayerData))
+		names := make([]string, 0, len(match.PlayerData))
+		for _, rating := range as.MatchRatings(match) {
+			ratings[rating.Name] = rating.Rating
+			names = append(names, rating.Name)
+		}
+		sort.Strings(names)
+
+		addLineups(duos, combinations(names, 2), match.Won, ratings)
+		addLineups(trios, combinations(names, 3), match.Won, ratings)
+		if len(names) == 5 {
+			addLineups(fives, [][]string{names}, match.Won, ratings)
+		}
+	}
+
+	report := &SynergyReport{
+		MinGames: minGames,
+		Duos:     sortedLineups(duos, minGames),
+		Trios:    sortedLineups(trios, minGames),
+		Fives:    sortedLineups(fives, minGames),
+	}
+
+	// Mejor y peor compañero: las duplas ya están ordenadas por win rate y rating
+	partners := make(map[string]*Partners)
+	for _, duo := range report.Duos {
+		for _, player := range duo.Players {
+			entry, ok := partners[player]
+			if !ok {
+				entry = &Partners{Player: player, Best: duo}
+				partners[player] = entry
+			}
+			entry.Worst = duo
+		}
+	}
+	for _, entry := range partners {
+		report.Partners = append(report.Partners, *entry)
+	}
+	sort.Slice(report.Partners, func(i, j int) bool {
+		return report.Partners[i].Player < report.Partners[j].Player
+	})
+
+	return report
+}
+
+// addLineups suma una partida a cada grupo
+func addLineups(lineups map[string]*Lineup, groups [][]string, won bool, ratings map[string]float64) {
+	for _, group := range groups {
+		key := strings.Join(group, "\x00")
+		lineup, ok := lineups[key]
+		if !ok {
+			lineup = &Lineup{Players: group}
+			lineups[key] = lineup
+		}
+
+		lineup.Games++
+		if won {
+			lineup.Wins++
+		}
+		total := 0.0
+		for _, name := range group {
+			total += ratings[name]
+		}
+		lineup.RatingTotal += total / float64(len(group))
+	}
+}
+
+// sortedLineups retorna los grupos con al menos minGames partidas, de mayor a menor win
+// rate (y por rating, partidas y nombre si empatan)
+func sortedLineups(lineups map[string]*Lineup, minGames int) []*Lineup {
+	sorted := make([]*Lineup, 0, len(lineups))
+	for _, lineup := range lineups {
+		if lineup.Games >= minGames {
+			sorted = append(sorted, lineup)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].WinRate() != sorted[j].WinRate() {
+			return sorted[i].WinRate() > sorted[j].WinRate()
+		}
+		if sorted[i].AverageRating() != sorted[j].AverageRating() {
+			return sorted[i].AverageRating() > sorted[j].AverageRating()
+		}
+		if sorted[i].Games != sorted[j].Games {
+			return sorted[i].Games > sorted[j].Games
+		}
+		return sorted[i].Label() < sorted[j].Label()
+	})
+	return sorted
+}
+
+// combinations retorna todos los grupos de k nombres (en el orden recibido)
+func combinations(names []string, k int) [][]string {
+	var groups [][]string
+	var build func(start int, group []string)
+	build = func(start int, group []string) {
+		if len(group) == k {
+			groups = append(groups, append([]string(nil), group...))
+			return
+		}
+		for i := start; i < len(names); i++ {
+			build(i+1, append(group, names[i]))
+		}
+	}
+	build(0, make([]string, 0, k))
+	return groups
+}
@@ -0,0 +1,220 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"valo-track/internal/models"
+)
+
+// Tipos de ventana de tiempo soportados por -timeframe
+const (
+	TimeframeAll     = "all"
+	TimeframeDaily   = "daily"
+	TimeframeWeekly  = "weekly"
+	TimeframeMonthly = "monthly"
+	TimeframeSeason  = "season"
+	TimeframeRecent  = "recent"
+	TimeframeRange   = "range"
+)
+
+// dateLayout es el formato de fecha aceptado por -from y -to
+const dateLayout = "2006-01-02"
+
+// Timeframe define qué partidas entran en el análisis
+type Timeframe struct {
+	Kind   string
+	From   time.Time // Inclusive (cero = sin límite)
+	To     time.Time // Exclusive (cero = sin límite)
+	Season string    // Season/acto (ej: e9a3) para TimeframeSeason; vacío = el más reciente
+	Recent int       // Cantidad de partidas para TimeframeRecent
+
+	// SeasonID es el acto elegido por Apply cuando la partida más reciente es de un acto
+	// que todavía no tiene nombre (solo partidas v2)
+	SeasonID string
+
+	// Unseasoned cuenta las partidas que TimeframeSeason dejó afuera por no tener season
+	// (las llena Apply)
+	Unseasoned int
+}
+
+// TimeframeOptions son los parámetros crudos de la línea de comandos
+type TimeframeOptions struct {
+	Kind    string // -timeframe explícito (vacío = no se pasó)
+	Default string // Ventana por defecto (VALO_TIMEFRAME) si no hay -timeframe ni -from/-to
+	Recent  int
+	Season  string
+	From    string // YYYY-MM-DD
+	To      string // YYYY-MM-DD, inclusive
+}
+
+// ParseTimeframe valida las opciones y arma la ventana relativa a now
+func ParseTimeframe(opts TimeframeOptions, now time.Time) (Timeframe, error) {
+	kind := strings.ToLower(strings.TrimSpace(opts.Kind))
+
+	// -from/-to definen un rango explícito: reemplazan la ventana por defecto, pero no
+	// se combinan con otro -timeframe explícito
+	if opts.From != "" || opts.To != "" {
+		if kind != "" && kind != TimeframeAll && kind != TimeframeRange {
+			return Timeframe{}, fmt.Errorf("-from/-to no se pueden combinar con -timeframe=%s", kind)
+		}
+		tf := Timeframe{Kind: TimeframeRange}
+		if opts.From != "" {
+			from, err := time.ParseInLocation(dateLayout, opts.From, now.Location())
+			if err != nil {
+				return Timeframe{}, fmt.Errorf("fecha -from inválida %q (formato AAAA-MM-DD)", opts.From)
+			}
+			tf.From = from
+		}
+		if opts.To != "" {
+			to, err := time.ParseInLocation(dateLayout, opts.To, now.Location())
+			if err != nil {
+				return Timeframe{}, fmt.Errorf("fecha -to inválida %q (formato AAAA-MM-DD)", opts.To)
+			}
+			tf.To = to.AddDate(0, 0, 1) // -to incluye el día completo
+		}
+		if !tf.From.IsZero() && !tf.To.IsZero() && !tf.From.Before(tf.To) {
+			return Timeframe{}, fmt.Errorf("-from (%s) debe ser anterior o igual a -to (%s)", opts.From, opts.To)
+		}
+		return tf, nil
+	}
+
+	if kind == "" {
+		kind = strings.ToLower(strings.TrimSpace(opts.Default))
+	}
+	if kind == "" {
+		kind = TimeframeAll
+	}
+
+	switch kind {
+	case TimeframeAll:
+		return Timeframe{Kind: kind}, nil
+	case TimeframeDaily:
+		return Timeframe{Kind: kind, From: now.Add(-24 * time.Hour)}, nil
+	case TimeframeWeekly:
+		return Timeframe{Kind: kind, From: now.AddDate(0, 0, -7)}, nil
+	case TimeframeMonthly:
+		return Timeframe{Kind: kind, From: now.AddDate(0, 0, -30)}, nil
+	case TimeframeSeason:
+		return Timeframe{Kind: kind, Season: strings.ToLower(strings.TrimSpace(opts.Season))}, nil
+	case TimeframeRecent:
+		if opts.Recent <= 0 {
+			return Timeframe{}, fmt.Errorf("-recent debe ser mayor a 0 (recibido %d)", opts.Recent)
+		}
+		return Timeframe{Kind: kind, Recent: opts.Recent}, nil
+	case TimeframeRange:
+		return Timeframe{}, fmt.Errorf("-timeframe=range requiere -from y/o -to")
+	default:
+		return Timeframe{}, fmt.Errorf("timeframe desconocido %q (opciones: all, daily, weekly, monthly, season, recent, o -from/-to)", kind)
+	}
+}
+
+// Apply retorna las partidas dentro de la ventana, ordenadas de la más vieja a la más
+// nueva. Para TimeframeSeason sin season explícita se usa la de la partida más reciente;
+// las partidas sin nombre de season (v2) lo toman de otra partida con el mismo SeasonID,
+// y si ninguna lo tiene el acto se identifica solo por SeasonID.
+func (tf *Timeframe) Apply(matches []models.MatchData) []models.MatchData {
+	sorted := make([]models.MatchData, len(matches))
+	copy(sorted, matches)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp < sorted[j].Timestamp
+	})
+
+	switch tf.Kind {
+	case TimeframeRecent:
+		if len(sorted) > tf.Recent {
+			sorted = sorted[len(sorted)-tf.Recent:]
+		}
+		return sorted
+
+	case TimeframeSeason:
+		sorted = fillSeasons(sorted)
+		if tf.Season == "" && tf.SeasonID == "" {
+			for i := len(sorted) - 1; i >= 0; i-- {
+				if sorted[i].Season != "" || sorted[i].SeasonID != "" {
+					tf.Season = strings.ToLower(sorted[i].Season)
+					if tf.Season == "" {
+						tf.SeasonID = sorted[i].SeasonID
+					}
+					break
+				}
+			}
+		}
+		tf.Unseasoned = 0
+		return filterMatches(sorted, func(match *models.MatchData) bool {
+			if tf.Season == "" && tf.SeasonID != "" {
+				if match.Season == "" && match.SeasonID == "" {
+					tf.Unseasoned++
+				}
+				return match.SeasonID == tf.SeasonID
+			}
+			if match.Season == "" {
+				tf.Unseasoned++
+				return false
+			}
+			return tf.Season != "" && strings.EqualFold(match.Season, tf.Season)
+		})
+	}
+
+	return filterMatches(sorted, func(match *models.MatchData) bool {
+		if !tf.From.IsZero() && match.Timestamp < tf.From.Unix() {
+			return false
+		}
+		if !tf.To.IsZero() && match.Timestamp >= tf.To.Unix() {
+			return false
+		}
+		return true
+	})
+}
+
+// fillSeasons completa el nombre de season de las partidas que solo tienen SeasonID
+// usando otra partida de la misma season que sí lo tenga
+func fillSeasons(matches []models.MatchData) []models.MatchData {
+	names := make(map[string]string)
+	for _, match := range matches {
+		if match.Season != "" && match.SeasonID != "" {
+			names[match.SeasonID] = match.Season
+		}
+	}
+
+	for i := range matches {
+		if matches[i].Season == "" {
+			matches[i].Season = names[matches[i].SeasonID]
+		}
+	}
+	return matches
+}
+
+// Describe retorna la ventana activa en texto, para el encabezado de los reportes
+func (tf Timeframe) Describe() string {
+	switch tf.Kind {
+	case TimeframeDaily:
+		return fmt.Sprintf("últimas 24 horas (desde %s)", tf.From.Format("2006-01-02 15:04"))
+	case TimeframeWeekly:
+		return fmt.Sprintf("últimos 7 días (desde %s)", tf.From.Format(dateLayout))
+	case TimeframeMonthly:
+		return fmt.Sprintf("últimos 30 días (desde %s)", tf.From.Format(dateLayout))
+	case TimeframeSeason:
+		if tf.Season == "" && tf.SeasonID != "" {
+			return "season actual (acto nuevo, todavía sin nombre)"
+		}
+		if tf.Season == "" {
+			return "season actual (sin datos de season)"
+		}
+		return fmt.Sprintf("season %s", strings.ToUpper(tf.Season))
+	case TimeframeRecent:
+		return fmt.Sprintf("últimas %d partidas", tf.Recent)
+	case TimeframeRange:
+		switch {
+		case tf.From.IsZero():
+			return fmt.Sprintf("hasta %s", tf.To.AddDate(0, 0, -1).Format(dateLayout))
+		case tf.To.IsZero():
+			return fmt.Sprintf("desde %s", tf.From.Format(dateLayout))
+		default:
+			return fmt.Sprintf("del %s al %s", tf.From.Format(dateLayout), tf.To.AddDate(0, 0, -1).Format(dateLayout))
+		}
+	default:
+		return "todo el histórico"
+	}
+}
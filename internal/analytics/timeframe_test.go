@@ -0,0 +1,209 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"valo-track/internal/models"
+)
+
+var testNow = time.Date(2025, 3, 15, 18, 30, 0, 0, time.UTC)
+
+func TestParseTimeframe(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		opts    TimeframeOptions
+		want    Timeframe
+		wantErr string
+	}{
+		{name: "sin opciones", opts: TimeframeOptions{}, want: Timeframe{Kind: TimeframeAll}},
+		{name: "all", opts: TimeframeOptions{Kind: "all"}, want: Timeframe{Kind: TimeframeAll}},
+		{name: "daily", opts: TimeframeOptions{Kind: "daily"}, want: Timeframe{Kind: TimeframeDaily, From: testNow.Add(-24 * time.Hour)}},
+		{name: "weekly con mayúsculas", opts: TimeframeOptions{Kind: " Weekly "}, want: Timeframe{Kind: TimeframeWeekly, From: testNow.AddDate(0, 0, -7)}},
+		{name: "monthly", opts: TimeframeOptions{Kind: "monthly"}, want: Timeframe{Kind: TimeframeMonthly, From: testNow.AddDate(0, 0, -30)}},
+		{name: "season por defecto", opts: TimeframeOptions{Kind: "season"}, want: Timeframe{Kind: TimeframeSeason}},
+		{name: "season explícita", opts: TimeframeOptions{Kind: "season", Season: "E9A3"}, want: Timeframe{Kind: TimeframeSeason, Season: "e9a3"}},
+		{name: "recent", opts: TimeframeOptions{Kind: "recent", Recent: 10}, want: Timeframe{Kind: TimeframeRecent, Recent: 10}},
+		{name: "recent sin cantidad", opts: TimeframeOptions{Kind: "recent"}, wantErr: "-recent debe ser mayor a 0"},
+		{name: "range sin fechas", opts: TimeframeOptions{Kind: "range"}, wantErr: "requiere -from"},
+		{name: "desconocido", opts: TimeframeOptions{Kind: "yearly"}, wantErr: "timeframe desconocido"},
+
+		{name: "default de entorno", opts: TimeframeOptions{Default: "weekly"}, want: Timeframe{Kind: TimeframeWeekly, From: testNow.AddDate(0, 0, -7)}},
+		{name: "flag sobre default", opts: TimeframeOptions{Kind: "daily", Default: "weekly"}, want: Timeframe{Kind: TimeframeDaily, From: testNow.Add(-24 * time.Hour)}},
+		{name: "default inválido", opts: TimeframeOptions{Default: "yearly"}, wantErr: "timeframe desconocido"},
+
+		{name: "from y to", opts: TimeframeOptions{From: "2025-01-01", To: "2025-01-31"}, want: Timeframe{Kind: TimeframeRange, From: day(2025, 1, 1), To: day(2025, 2, 1)}},
+		{name: "solo from", opts: TimeframeOptions{From: "2025-01-01"}, want: Timeframe{Kind: TimeframeRange, From: day(2025, 1, 1)}},
+		{name: "solo to incluye el día", opts: TimeframeOptions{To: "2025-01-31"}, want: Timeframe{Kind: TimeframeRange, To: day(2025, 2, 1)}},
+		{name: "from igual a to", opts: TimeframeOptions{From: "2025-01-31", To: "2025-01-31"}, want: Timeframe{Kind: TimeframeRange, From: day(2025, 1, 31), To: day(2025, 2, 1)}},
+		{name: "from posterior a to", opts: TimeframeOptions{From: "2025-02-01", To: "2025-01-31"}, wantErr: "debe ser anterior"},
+		{name: "from inválido", opts: TimeframeOptions{From: "01/02/2025"}, wantErr: "fecha -from inválida"},
+		{name: "to inválido", opts: TimeframeOptions{To: "2025-13-01"}, wantErr: "fecha -to inválida"},
+
+		// -from/-to reemplazan el default de VALO_TIMEFRAME; solo chocan con un -timeframe explícito
+		{name: "from sobre default de entorno", opts: TimeframeOptions{Default: "weekly", From: "2025-01-01"}, want: Timeframe{Kind: TimeframeRange, From: day(2025, 1, 1)}},
+		{name: "from con -timeframe=all", opts: TimeframeOptions{Kind: "all", From: "2025-01-01"}, want: Timeframe{Kind: TimeframeRange, From: day(2025, 1, 1)}},
+		{name: "from con -timeframe=range", opts: TimeframeOptions{Kind: "range", To: "2025-01-31"}, want: Timeframe{Kind: TimeframeRange, To: day(2025, 2, 1)}},
+		{name: "from con -timeframe explícito", opts: TimeframeOptions{Kind: "weekly", From: "2025-01-01"}, wantErr: "no se pueden combinar con -timeframe=weekly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimeframe(tt.opts, testNow)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if got.Kind != tt.want.Kind || !got.From.Equal(tt.want.From) || !got.To.Equal(tt.want.To) ||
+				got.Season != tt.want.Season || got.Recent != tt.want.Recent {
+				t.Errorf("ParseTimeframe() = %+v, se esperaba %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeframeApply(t *testing.T) {
+	at := func(y int, m time.Month, d, h int) int64 {
+		return time.Date(y, m, d, h, 0, 0, 0, time.UTC).Unix()
+	}
+
+	// Desordenadas a propósito: Apply ordena de la más vieja a la más nueva
+	matches := []models.MatchData{
+		{MatchID: "m3", Timestamp: at(2025, 3, 1, 12), Season: "e9a2"},
+		{MatchID: "m1", Timestamp: at(2025, 1, 1, 0), Season: "e9a1"},
+		{MatchID: "m5", Timestamp: at(2025, 3, 15, 12), Season: "E9A2"},
+		{MatchID: "m2", Timestamp: at(2025, 1, 31, 23), Season: "e9a1"},
+		{MatchID: "m4", Timestamp: at(2025, 3, 10, 12), Season: ""},
+		{MatchID: "m0", Timestamp: at(2024, 12, 31, 23), Season: "e9a1"},
+	}
+
+	tests := []struct {
+		name       string
+		opts       TimeframeOptions
+		want       []string
+		wantSeason string
+	}{
+		{name: "all ordena por fecha", opts: TimeframeOptions{}, want: []string{"m0", "m1", "m2", "m3", "m4", "m5"}},
+		{name: "daily", opts: TimeframeOptions{Kind: "daily"}, want: []string{"m5"}},
+		{name: "weekly", opts: TimeframeOptions{Kind: "weekly"}, want: []string{"m4", "m5"}},
+		{name: "monthly", opts: TimeframeOptions{Kind: "monthly"}, want: []string{"m3", "m4", "m5"}},
+		{name: "recent", opts: TimeframeOptions{Kind: "recent", Recent: 2}, want: []string{"m4", "m5"}},
+		{name: "recent mayor al total", opts: TimeframeOptions{Kind: "recent", Recent: 50}, want: []string{"m0", "m1", "m2", "m3", "m4", "m5"}},
+		{name: "season por defecto es la más reciente", opts: TimeframeOptions{Kind: "season"}, want: []string{"m3", "m5"}, wantSeason: "e9a2"},
+		{name: "season explícita", opts: TimeframeOptions{Kind: "season", Season: "e9a1"}, want: []string{"m0", "m1", "m2"}, wantSeason: "e9a1"},
+		{name: "season sin partidas", opts: TimeframeOptions{Kind: "season", Season: "e8a3"}, want: nil, wantSeason: "e8a3"},
+		{name: "from/to son inclusivos", opts: TimeframeOptions{From: "2025-01-01", To: "2025-01-31"}, want: []string{"m1", "m2"}},
+		{name: "solo from", opts: TimeframeOptions{From: "2025-03-10"}, want: []string{"m4", "m5"}},
+		{name: "solo to", opts: TimeframeOptions{To: "2024-12-31"}, want: []string{"m0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tf, err := ParseTimeframe(tt.opts, testNow)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			var got []string
+			for _, match := range tf.Apply(matches) {
+				got = append(got, match.MatchID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Apply() = %v, se esperaba %v", got, tt.want)
+			}
+			if tt.wantSeason != "" && tf.Season != tt.wantSeason {
+				t.Errorf("Season = %q, se esperaba %q", tf.Season, tt.wantSeason)
+			}
+		})
+	}
+}
+
+func TestTimeframeApplyV2Seasons(t *testing.T) {
+	matches := []models.MatchData{
+		{MatchID: "v2-otra", Timestamp: 0, SeasonID: "s1"}, // Acto anterior sin partida v4
+		{MatchID: "v4", Timestamp: 1, Season: "e9a2", SeasonID: "s2"},
+		{MatchID: "v2", Timestamp: 2, SeasonID: "s2"}, // Toma e9a2 de la partida v4
+		{MatchID: "sin-id", Timestamp: 4},             // Importada antes de guardar el SeasonID
+	}
+
+	tf, err := ParseTimeframe(TimeframeOptions{Kind: "season"}, testNow)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	var got []string
+	for _, match := range tf.Apply(matches) {
+		got = append(got, match.MatchID)
+	}
+	if strings.Join(got, ",") != "v4,v2" {
+		t.Errorf("Apply() = %v, se esperaba [v4 v2]", got)
+	}
+	if tf.Season != "e9a2" {
+		t.Errorf("Season = %q, se esperaba e9a2", tf.Season)
+	}
+	if tf.Unseasoned != 2 {
+		t.Errorf("Unseasoned = %d, se esperaba 2", tf.Unseasoned)
+	}
+	if matches[2].Season != "" {
+		t.Error("Apply() modificó las partidas recibidas")
+	}
+
+	// Otras ventanas no cuentan partidas sin season
+	all, _ := ParseTimeframe(TimeframeOptions{}, testNow)
+	if all.Apply(matches); all.Unseasoned != 0 {
+		t.Errorf("Unseasoned con -timeframe=all = %d, se esperaba 0", all.Unseasoned)
+	}
+}
+
+func TestTimeframeApplyNewSeasonOnlyV2(t *testing.T) {
+	// El acto nuevo (s3) solo tiene partidas de la lista v2: no hay de dónde sacar el nombre
+	matches := []models.MatchData{
+		{MatchID: "viejo-v4", Timestamp: 1, Season: "e9a2", SeasonID: "s2"},
+		{MatchID: "viejo-v2", Timestamp: 2, SeasonID: "s2"},
+		{MatchID: "sin-id", Timestamp: 3},
+		{MatchID: "nuevo-1", Timestamp: 4, SeasonID: "s3"},
+		{MatchID: "nuevo-2", Timestamp: 5, SeasonID: "s3"},
+	}
+
+	tf, err := ParseTimeframe(TimeframeOptions{Kind: "season"}, testNow)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	var got []string
+	for _, match := range tf.Apply(matches) {
+		got = append(got, match.MatchID)
+	}
+	if strings.Join(got, ",") != "nuevo-1,nuevo-2" {
+		t.Errorf("Apply() = %v, se esperaba [nuevo-1 nuevo-2] y no el acto anterior", got)
+	}
+	if tf.Season != "" || tf.SeasonID != "s3" {
+		t.Errorf("Season = %q, SeasonID = %q; se esperaba el acto s3 sin nombre", tf.Season, tf.SeasonID)
+	}
+	if tf.Unseasoned != 1 {
+		t.Errorf("Unseasoned = %d, se esperaba 1", tf.Unseasoned)
+	}
+	if desc := tf.Describe(); !strings.Contains(desc, "sin nombre") {
+		t.Errorf("Describe() = %q, se esperaba que avise que el acto no tiene nombre", desc)
+	}
+
+	// Cuando llega una partida v4 del acto, el acto se identifica por nombre
+	matches = append(matches, models.MatchData{MatchID: "nuevo-v4", Timestamp: 6, Season: "e10a1", SeasonID: "s3"})
+	tf, _ = ParseTimeframe(TimeframeOptions{Kind: "season"}, testNow)
+	got = nil
+	for _, match := range tf.Apply(matches) {
+		got = append(got, match.MatchID)
+	}
+	if strings.Join(got, ",") != "nuevo-1,nuevo-2,nuevo-v4" || tf.Season != "e10a1" {
+		t.Errorf("Apply() = %v en %q, se esperaba [nuevo-1 nuevo-2 nuevo-v4] en e10a1", got, tf.Season)
+	}
+}
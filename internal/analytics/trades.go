@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"sort"
+	"valo-track/internal/models"
+)
+
+// TradeStats resume los trades de un jugador del stack
+type TradeStats struct {
+	Name         string
+	Deaths       int
+	TradedDeaths int // Muertes vengadas por cualquier compañero
+	TradeKills   int // Kills vengando a un compañero del stack
+	TradeTimeMs  int // Suma del tiempo de reacción de sus trade kills
+}
+
+// UntradedPct retorna el porcentaje de muertes que nadie tradeó
+func (ts *TradeStats) UntradedPct() float64 {
+	return ratio(float64(ts.Deaths-ts.TradedDeaths)*100, float64(ts.Deaths))
+}
+
+// AverageTradeTime retorna el tiempo promedio de sus trade kills, en segundos
+func (ts *TradeStats) AverageTradeTime() float64 {
+	return ratio(float64(ts.TradeTimeMs)/1000, float64(ts.TradeKills))
+}
+
+// TradeReport contiene la red de trades del stack
+type TradeReport struct {
+	Players []*TradeStats             // Ordenados por nombre
+	Matrix  map[string]map[string]int // [víctima][quién tradeó] = cantidad
+}
+
+// Count retorna cuántas veces trader vengó la muerte de victim
+func (tr *TradeReport) Count(victim, trader string) int {
+	return tr.Matrix[victim][trader]
+}
+
+// AnalyzeTrades arma la matriz de trades entre jugadores del stack (A murió, B lo tradeó
+// dentro de la ventana de trade), las trade kills de cada uno, el porcentaje de muertes
+// sin tradear y el tiempo promedio de reacción
+func (as *AnalyticsService) AnalyzeTrades(matches []models.MatchData) *TradeReport {
+	players := make(map[string]*TradeStats)
+	player := func(name string) *TradeStats {
+		stats, ok := players[name]
+		if !ok {
+			stats = &TradeStats{Name: name}
+			players[name] = stats
+		}
+		return stats
+	}
+
+	report := &TradeReport{Matrix: make(map[string]map[string]int)}
+	for _, match := range matches {
+		eventsByRound := groupKillsByRound(match.Kills)
+
+		for _, events := range eventsByRound {
+			for _, ev := range events {
+				if ev.VictimName != "" {
+					player(ev.VictimName).Deaths++
+				}
+			}
+		}
+
+		for _, trade := range as.FindTrades(eventsByRound) {
+			player(trade.Victim).TradedDeaths++
+			if trade.Trader == "" {
+				continue
+			}
+
+			trader := player(trade.Trader)
+			trader.TradeKills++
+			trader.TradeTimeMs += trade.DelayMs
+
+			if _, ok := report.Matrix[trade.Victim]; !ok {
+				report.Matrix[trade.Victim] = make(map[string]int)
+			}
+			report.Matrix[trade.Victim][trade.Trader]++
+		}
+	}
+
+	for _, stats := range players {
+		report.Players = append(report.Players, stats)
+	}
+	sort.Slice(report.Players, func(i, j int) bool {
+		return report.Players[i].Name < report.Players[j].Name
+	})
+
+	return report
+}
@@ -0,0 +1,174 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"valo-track/internal/models"
+)
+
+// Clases de arma, en orden de presentación
+const (
+	ClassSidearm = "sidearm"
+	ClassSMG     = "smg"
+	ClassShotgun = "shotgun"
+	ClassRifle   = "rifle"
+	ClassSniper  = "sniper"
+	ClassHeavy   = "heavy"
+	ClassMelee   = "melee"
+	ClassAbility = "ability" // Habilidades y ultis
+	ClassOther   = "other"
+)
+
+// WeaponClasses son las clases en orden de presentación
+var WeaponClasses = []string{ClassSidearm, ClassSMG, ClassShotgun, ClassRifle, ClassSniper, ClassHeavy, ClassMelee, ClassAbility, ClassOther}
+
+// AbilityWeapon es el nombre con el que se guardan las kills con habilidades
+const AbilityWeapon = "Ability"
+
+// weaponClasses asocia cada arma (en minúsculas) con su clase
+var weaponClasses = map[string]string{
+	"classic": ClassSidearm, "shorty": ClassSidearm, "frenzy": ClassSidearm,
+	"ghost": ClassSidearm, "sheriff": ClassSidearm, "bandit": ClassSidearm,
+	"stinger": ClassSMG, "spectre": ClassSMG,
+	"bucky": ClassShotgun, "judge": ClassShotgun,
+	"bulldog": ClassRifle, "guardian": ClassRifle, "phantom": ClassRifle, "vandal": ClassRifle,
+	"marshal": ClassSniper, "outlaw": ClassSniper, "operator": ClassSniper,
+	"ares": ClassHeavy, "odin": ClassHeavy,
+	"melee": ClassMelee, "knife": ClassMelee,
+}
+
+// WeaponClass retorna la clase de un arma por su nombre
+func WeaponClass(weapon string) string {
+	if weapon == AbilityWeapon {
+		return ClassAbility
+	}
+	if class, ok := weaponClasses[strings.ToLower(weapon)]; ok {
+		return class
+	}
+	return ClassOther
+}
+
+// KillWeapon retorna el arma de una kill; las kills con habilidades y ultis (sin nombre de
+// arma) se agrupan como AbilityWeapon. Retorna vacío si la kill no trae arma.
+func KillWeapon(kill models.V4KillEventResponse) string {
+	switch {
+	case kill.Weapon.Name == "" && kill.Weapon.Type == "":
+		return ""
+	case kill.Weapon.Name == "",
+		strings.EqualFold(kill.Weapon.Type, "ability"),
+		strings.EqualFold(kill.Weapon.Type, "ultimate"):
+		return AbilityWeapon
+	default:
+		return kill.Weapon.Name
+	}
+}
+
+// CalculateWeapons acumula por jugador del stack las kills con cada arma y, para el arma
+// comprada en cada ronda, las rondas, el daño y los disparos a cabeza/cuerpo/piernas
+func (as *AnalyticsService) CalculateWeapons(rounds []models.V4Round, kills []models.V4KillEventResponse, match *models.MatchData, stackNamesByPUUID map[string]string) {
+	weapons := func(playerName string) map[string]models.WeaponStats {
+		if _, ok := match.Weapons[playerName]; !ok {
+			match.Weapons[playerName] = make(map[string]models.WeaponStats)
+		}
+		return match.Weapons[playerName]
+	}
+
+	for _, kill := range kills {
+		playerName := stackNamesByPUUID[kill.Killer.PUUID]
+		weapon := KillWeapon(kill)
+		if playerName == "" || weapon == "" || kill.Killer.Team == kill.Victim.Team {
+			continue
+		}
+		byWeapon := weapons(playerName)
+		stats := byWeapon[weapon]
+		stats.Kills++
+		byWeapon[weapon] = stats
+	}
+
+	for _, round := range rounds {
+		for _, stat := range round.Stats {
+			playerName := stackNamesByPUUID[stat.Player.PUUID]
+			if playerName == "" || stat.Economy == nil || stat.Economy.Weapon == nil || stat.Economy.Weapon.Name == "" {
+				continue
+			}
+			byWeapon := weapons(playerName)
+			stats := byWeapon[stat.Economy.Weapon.Name]
+			stats.Rounds++
+			stats.Damage += stat.Stats.Damage
+			stats.Headshots += stat.Stats.Headshots
+			stats.Bodyshots += stat.Stats.Bodyshots
+			stats.Legshots += stat.Stats.Legshots
+			byWeapon[stat.Economy.Weapon.Name] = stats
+		}
+	}
+}
+
+// addWeaponStats suma los stats de un arma de otra partida
+func addWeaponStats(total, other models.WeaponStats) models.WeaponStats {
+	total.Kills += other.Kills
+	total.Rounds += other.Rounds
+	total.Damage += other.Damage
+	total.Headshots += other.Headshots
+	total.Bodyshots += other.Bodyshots
+	total.Legshots += other.Legshots
+	return total
+}
+
+// WeaponLine son los stats de un arma (o de una clase completa)
+type WeaponLine struct {
+	Name string
+	models.WeaponStats
+}
+
+// KillsPerRound retorna las kills por ronda con el arma comprada
+func (wl WeaponLine) KillsPerRound() float64 {
+	return ratio(float64(wl.Kills), float64(wl.Rounds))
+}
+
+// DamagePerRound retorna el daño por ronda con el arma comprada
+func (wl WeaponLine) DamagePerRound() float64 {
+	return ratio(float64(wl.Damage), float64(wl.Rounds))
+}
+
+// HSPct retorna el porcentaje de headshots en las rondas con el arma comprada
+func (wl WeaponLine) HSPct() float64 {
+	return ratio(float64(wl.Headshots)*100, float64(wl.Headshots+wl.Bodyshots+wl.Legshots))
+}
+
+// WeaponClassStats agrupa las armas de una clase con su total
+type WeaponClassStats struct {
+	Total   WeaponLine   // Name = clase
+	Weapons []WeaponLine // De más a menos kills
+}
+
+// WeaponBreakdown agrupa los stats por arma de un jugador por clase, en el orden de
+// WeaponClasses. Las clases sin datos se omiten.
+func WeaponBreakdown(stats *models.PlayerStats) []WeaponClassStats {
+	byClass := make(map[string]*WeaponClassStats)
+	for weapon, weaponStats := range stats.Weapons {
+		class := WeaponClass(weapon)
+		classStats, ok := byClass[class]
+		if !ok {
+			classStats = &WeaponClassStats{Total: WeaponLine{Name: class}}
+			byClass[class] = classStats
+		}
+		classStats.Total.WeaponStats = addWeaponStats(classStats.Total.WeaponStats, weaponStats)
+		classStats.Weapons = append(classStats.Weapons, WeaponLine{Name: weapon, WeaponStats: weaponStats})
+	}
+
+	breakdown := make([]WeaponClassStats, 0, len(byClass))
+	for _, class := range WeaponClasses {
+		classStats, ok := byClass[class]
+		if !ok {
+			continue
+		}
+		sort.Slice(classStats.Weapons, func(i, j int) bool {
+			if classStats.Weapons[i].Kills != classStats.Weapons[j].Kills {
+				return classStats.Weapons[i].Kills > classStats.Weapons[j].Kills
+			}
+			return classStats.Weapons[i].Name < classStats.Weapons[j].Name
+		})
+		breakdown = append(breakdown, *classStats)
+	}
+	return breakdown
+}
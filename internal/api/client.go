@@ -1,22 +1,33 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
 	"time"
 	"valo-track/internal/models"
 )
 
 // APIClient gestiona las llamadas a la API de Valorant
 type APIClient struct {
-	baseURL        string
-	apiKey         string
-	region         string
-	httpClient     *http.Client
-	maxRetries     int
-	retryDelay     time.Duration
+	baseURL    string
+	apiKey     string
+	region     string
+	httpClient *http.Client
+	maxRetries int
+	retryDelay time.Duration
+	archive    RawArchiver
+	limiter    *RateLimiter
+}
+
+// RawArchiver persiste el body crudo de las respuestas de partidas
+type RawArchiver interface {
+	SaveRaw(matchID string, body []byte) error
 }
 
 // NewAPIClient crea un nuevo cliente de API
@@ -33,19 +44,40 @@ func NewAPIClient(apiKey, region string, timeout time.Duration, maxRetries int)
 	}
 }
 
+// SetBaseURL reemplaza la URL base de la API (por ejemplo, para usar cmd/fake-henrik)
+func (ac *APIClient) SetBaseURL(baseURL string) {
+	ac.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetTransport reemplaza el transporte HTTP (ver NewTransport para record/replay)
+func (ac *APIClient) SetTransport(transport http.RoundTripper) {
+	ac.httpClient.Transport = transport
+}
+
+// SetRateLimiter hace que cada request HTTP (incluidos los reintentos) pase por el
+// limitador. Compartirlo entre clientes reparte el mismo cupo.
+func (ac *APIClient) SetRateLimiter(limiter *RateLimiter) {
+	ac.limiter = limiter
+}
+
+// SetArchive configura dónde guardar las respuestas crudas de GetMatchDetailsV4
+func (ac *APIClient) SetArchive(archive RawArchiver) {
+	ac.archive = archive
+}
+
 // GetLifetimeMatches obtiene las partidas competitivas de un jugador (v3)
-func (ac *APIClient) GetLifetimeMatches(name, tag string, queueMode string) ([]string, error) {
+func (ac *APIClient) GetLifetimeMatches(ctx context.Context, name, tag string, queueMode string) ([]string, error) {
 	url := fmt.Sprintf("%s/v3/by-puuid/account/%s/%s", ac.baseURL, ac.region, queueMode)
 
 	// Primero obtener el PUUID del jugador
-	puuid, err := ac.GetPlayerPUUID(name, tag)
+	puuid, err := ac.GetPlayerPUUID(ctx, name, tag)
 	if err != nil {
 		return nil, fmt.Errorf("error obteniendo PUUID de %s#%s: %w", name, tag, err)
 	}
 
 	url = fmt.Sprintf("%s/v3/by-puuid/account/%s/%s", ac.baseURL, puuid, queueMode)
 
-	body, err := ac.makeRequest(url)
+	body, err := ac.makeRequest(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -56,21 +88,21 @@ func (ac *APIClient) GetLifetimeMatches(name, tag string, queueMode string) ([]s
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error decodificando response de v3: %w", err)
+		return nil, &DecodeError{What: "response de v3", Err: err}
 	}
 
-	if response.Status != 200 {
-		return nil, fmt.Errorf("API error: status code %d", response.Status)
+	if err := checkStatus(response.Status); err != nil {
+		return nil, err
 	}
 
 	return response.Data, nil
 }
 
 // GetPlayerPUUID obtiene el PUUID de un jugador
-func (ac *APIClient) GetPlayerPUUID(name, tag string) (string, error) {
-	url := fmt.Sprintf("%s/v1/account/%s/%s/%s", ac.baseURL, ac.region, name, tag)
+func (ac *APIClient) GetPlayerPUUID(ctx context.Context, name, tag string) (string, error) {
+	url := fmt.Sprintf("%s/v1/account/%s/%s/%s", ac.baseURL, ac.region, neturl.PathEscape(name), neturl.PathEscape(tag))
 
-	body, err := ac.makeRequest(url)
+	body, err := ac.makeRequest(ctx, url)
 	if err != nil {
 		return "", err
 	}
@@ -83,193 +115,246 @@ func (ac *APIClient) GetPlayerPUUID(name, tag string) (string, error) {
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("error decodificando PUUID: %w", err)
+		return "", &DecodeError{What: "PUUID", Err: err}
 	}
 
-	if response.Status != 200 {
-		return "", fmt.Errorf("API error al obtener PUUID: status code %d", response.Status)
+	if err := checkStatus(response.Status); err != nil {
+		return "", err
 	}
 
 	return response.Data.PUUID, nil
 }
 
 // GetMatchDetailsV4 obtiene los detalles completos de una partida (v4)
-func (ac *APIClient) GetMatchDetailsV4(matchID string) (*V4MatchResponse, error) {
+func (ac *APIClient) GetMatchDetailsV4(ctx context.Context, matchID string) (*V4MatchResponse, error) {
 	url := fmt.Sprintf("%s/v4/match/%s/%s", ac.baseURL, ac.region, matchID)
 
-	body, err := ac.makeRequest(url)
+	body, err := ac.makeRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := DecodeMatchV4(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Se archiva con el ID de la respuesta: en replay un mismo fixture responde a todos
+	ac.archiveRaw(response.Data.Metadata.MatchID, body)
+
+	return response, nil
+}
+
+// GetMatchDetailsV2 obtiene los detalles de una partida con el endpoint v2 y los retorna
+// convertidos a v4
+func (ac *APIClient) GetMatchDetailsV2(ctx context.Context, matchID string) (*V4MatchResponse, error) {
+	url := fmt.Sprintf("%s/v2/match/%s", ac.baseURL, matchID)
+
+	body, err := ac.makeRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := DecodeMatchV2(body)
+	if err != nil {
+		return nil, err
+	}
+
+	ac.archiveRaw(response.Data.Metadata.MatchID, body)
+
+	return response, nil
+}
+
+// GetMatchList obtiene las últimas partidas completas de un jugador en una sola request
+// (v3/matches, en formato v2) y las retorna convertidas a v4. La API devuelve como máximo
+// 10 partidas por llamada.
+func (ac *APIClient) GetMatchList(ctx context.Context, name, tag, queueMode string, size int) ([]*V4MatchResponse, error) {
+	url := fmt.Sprintf("%s/v3/matches/%s/%s/%s?mode=%s&size=%d", ac.baseURL, ac.region,
+		neturl.PathEscape(name), neturl.PathEscape(tag), neturl.QueryEscape(queueMode), size)
+
+	body, err := ac.makeRequest(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 
+	bodies, err := SplitMatches(body)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*V4MatchResponse, 0, len(bodies))
+	for _, matchBody := range bodies {
+		response, err := DecodeMatchV2(matchBody)
+		if err != nil {
+			return nil, err
+		}
+		ac.archiveRaw(response.Data.Metadata.MatchID, matchBody)
+		matches = append(matches, response)
+	}
+
+	return matches, nil
+}
+
+// archiveRaw guarda la respuesta cruda de una partida para poder recalcular sin volver a
+// descargarla (v2 o v4: ReprocessArchive las lee con DecodeMatch)
+func (ac *APIClient) archiveRaw(matchID string, body []byte) {
+	if ac.archive == nil || matchID == "" {
+		return
+	}
+	if err := ac.archive.SaveRaw(matchID, body); err != nil {
+		fmt.Printf("  ⚠️  No se pudo archivar la partida %s: %v\n", matchID, err)
+	}
+}
+
+// DecodeMatchV4 decodifica el body de una respuesta v4 de detalles de partida
+func DecodeMatchV4(body []byte) (*V4MatchResponse, error) {
 	var response V4MatchResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error decodificando match details v4: %w", err)
+		return nil, &DecodeError{What: "match details v4", Err: err}
 	}
 
-	if response.Status != 200 {
-		return nil, fmt.Errorf("API error al obtener match details: status code %d", response.Status)
+	if err := checkStatus(response.Status); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
 }
 
-// makeRequest realiza una request HTTP con reintentos automáticos
-func (ac *APIClient) makeRequest(url string) ([]byte, error) {
+// makeRequest realiza una request HTTP con reintentos automáticos. Cancelar ctx corta la
+// request en curso y cualquier espera entre reintentos. Los errores de la API son
+// *StatusError o *RateLimitError; si se agotan los reintentos, *RetriesError.
+func (ac *APIClient) makeRequest(ctx context.Context, url string) ([]byte, error) {
 	var lastErr error
+	var delay time.Duration
 
 	for attempt := 0; attempt <= ac.maxRetries; attempt++ {
 		if attempt > 0 {
-			time.Sleep(ac.retryDelay * time.Duration(attempt))
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, err
+			}
 		}
 
-		req, err := http.NewRequest(http.MethodGet, url, nil)
+		// Cada intento cuenta contra el cupo de la API
+		if ac.limiter != nil {
+			if err := ac.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
 			return nil, fmt.Errorf("error creando request: %w", err)
 		}
 
 		req.Header.Add("Authorization", ac.apiKey)
 
+		delay = backoff(ac.retryDelay, attempt+1)
+
 		resp, err := ac.httpClient.Do(req)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			lastErr = err
 			continue
 		}
-		defer resp.Body.Close()
+
+		if ac.limiter != nil {
+			ac.limiter.Observe(resp.Header)
+		}
 
 		// Leer el body
 		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			lastErr = fmt.Errorf("error leyendo response body: %w", err)
 			continue
 		}
 
 		// Manejo de status codes
 		if resp.StatusCode == http.StatusTooManyRequests {
-			// 429 - Rate limited, esperar antes de reintentar
-			retryAfter := resp.Header.Get("Retry-After")
-			if retryAfter != "" {
-				retrySeconds := 60 // Default a 60 segundos
-				fmt.Sscanf(retryAfter, "%d", &retrySeconds)
-				time.Sleep(time.Duration(retrySeconds) * time.Second)
-				lastErr = fmt.Errorf("rate limited, esperando %d segundos", retrySeconds)
-				continue
+			// 429 - Rate limited: esperar lo que indique la API (Retry-After o
+			// x-ratelimit-reset) o, si no lo indica, un backoff exponencial con jitter
+			rateErr := &RateLimitError{RetryAfter: retryAfter(resp.Header, time.Now())}
+			lastErr = rateErr
+			if rateErr.RetryAfter > 0 {
+				delay = rateErr.RetryAfter + backoff(ac.retryDelay, 1)
+			}
+			if ac.limiter != nil {
+				// Pausar también al resto de las requests que comparten el cupo
+				ac.limiter.Pause(delay)
 			}
-			lastErr = fmt.Errorf("rate limited (429)")
 			continue
 		}
 
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-			lastErr = fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
-			// No reintentar en algunos errores
-			if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized {
-				return nil, lastErr
+			statusErr := &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+			// Los errores del cliente (404, 401, 403...) no se arreglan reintentando
+			if !statusErr.retryable() {
+				return nil, statusErr
 			}
+			lastErr = statusErr
 			continue
 		}
 
 		return body, nil
 	}
 
-	return nil, fmt.Errorf("se agotaron los reintentos: %w", lastErr)
+	return nil, &RetriesError{Attempts: ac.maxRetries + 1, Err: lastErr}
 }
 
-// Structs para respuestas de API v4
-
-type V4MatchPlayer struct {
-	PUUID  string `json:"puuid"`
-	Name   string `json:"name"`
-	Tag    string `json:"tag"`
-	TeamID string `json:"team_id"`
-	Agent  struct {
-		Name string `json:"name"`
-	} `json:"agent"`
-	Stats struct {
-		Score     int `json:"score"`
-		Kills     int `json:"kills"`
-		Deaths    int `json:"deaths"`
-		Assists   int `json:"assists"`
-		Headshots int `json:"headshots"`
-		Bodyshots int `json:"bodyshots"`
-		Legshots  int `json:"legshots"`
-		Damage    struct {
-			Dealt    int `json:"dealt"`
-			Received int `json:"received"`
-		} `json:"damage"`
-	} `json:"stats"`
+// retryAfter retorna la espera que pide la API en un 429: Retry-After o, si falta,
+// x-ratelimit-reset. Retorna 0 si no indica ninguna.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	if wait := parseRetryAfter(header.Get("Retry-After"), now); wait > 0 {
+		return wait
+	}
+	if reset, ok := headerInt(header, "X-Ratelimit-Reset"); ok && reset > 0 {
+		return time.Duration(reset) * time.Second
+	}
+	return 0
 }
 
-type V4RoundStatsEntry struct {
-	Player struct {
-		PUUID string `json:"puuid"`
-		Name  string `json:"name"`
-		Tag   string `json:"tag"`
-		Team  string `json:"team"`
-	} `json:"player"`
-	Stats struct {
-		Damage int `json:"damage"`
-		Kills  int `json:"kills"`
-	} `json:"stats"`
+// parseRetryAfter interpreta el header Retry-After (segundos o fecha HTTP). Retorna 0
+// si falta o no se entiende.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return 0
 }
 
-type V4Round struct {
-	ID          int    `json:"id"`
-	WinningTeam string `json:"winning_team"`
-	Plant       *struct {
-		Player struct {
-			Team string `json:"team"`
-		} `json:"player"`
-	} `json:"plant"`
-	Stats []V4RoundStatsEntry `json:"stats"`
-}
+// sleepContext espera d o hasta que se cancele ctx, lo que pase primero
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-type V4KillEventResponse struct {
-	Round           int `json:"round"`
-	TimeInRoundInMs int `json:"time_in_round_in_ms"`
-	Killer          struct {
-		PUUID string `json:"puuid"`
-		Name  string `json:"name"`
-		Tag   string `json:"tag"`
-		Team  string `json:"team"`
-	} `json:"killer"`
-	Victim struct {
-		PUUID string `json:"puuid"`
-		Name  string `json:"name"`
-		Tag   string `json:"tag"`
-		Team  string `json:"team"`
-	} `json:"victim"`
-	Assistants []struct {
-		PUUID string `json:"puuid"`
-		Name  string `json:"name"`
-		Tag   string `json:"tag"`
-		Team  string `json:"team"`
-	} `json:"assistants"`
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-type V4MatchResponse struct {
-	Status int `json:"status"`
-	Data   struct {
-		ID       string `json:"id"`
-		Metadata struct {
-			Map struct {
-				Name string `json:"name"`
-			} `json:"map"`
-			Queue struct {
-				ID string `json:"id"`
-			} `json:"queue"`
-			Region    string `json:"region"`
-			GameStart int64  `json:"game_start"`
-		} `json:"metadata"`
-		Players []V4MatchPlayer `json:"players"`
-		Teams   []struct {
-			TeamID string `json:"team_id"`
-			Rounds struct {
-				Won  int `json:"won"`
-				Lost int `json:"lost"`
-			} `json:"rounds"`
-			Won bool `json:"won"`
-		} `json:"teams"`
-		Rounds []V4Round              `json:"rounds"`
-		Kills  []V4KillEventResponse `json:"kills"`
-	} `json:"data"`
-}
+// Alias para compatibilidad con código existente
+type V4MatchResponse = models.V4MatchResponse
+type V4MatchPlayer = models.V4MatchPlayer
+type V4RoundStatsEntry = models.V4RoundStatsEntry
+type V4Round = models.V4Round
+type V4KillEventResponse = models.V4KillEventResponse
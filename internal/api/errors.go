@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Clases de error de la API. Se comparan con errors.Is; para los detalles (status,
+// Retry-After, causa) usar errors.As con los tipos de abajo.
+var (
+	ErrNotFound         = errors.New("no encontrado")
+	ErrUnauthorized     = errors.New("no autorizado")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrUpstream         = errors.New("error del servidor de la API")
+	ErrDecode           = errors.New("respuesta inválida")
+	ErrRetriesExhausted = errors.New("se agotaron los reintentos")
+)
+
+// StatusError es una respuesta de la API con status distinto de 200/201
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	body := strings.TrimSpace(e.Body)
+	if len(body) > 200 {
+		body = body[:200] + "..."
+	}
+	if body == "" {
+		return fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, body)
+}
+
+// Is clasifica el status: 404 → ErrNotFound, 401/403 → ErrUnauthorized, 5xx → ErrUpstream
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrUpstream:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// retryable indica si tiene sentido repetir la request (5xx y 408)
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout
+}
+
+// RateLimitError es una respuesta 429. RetryAfter es 0 si la API no indicó la espera.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited (429), reintentar en %s", e.RetryAfter)
+	}
+	return "rate limited (429)"
+}
+
+// Is permite errors.Is(err, ErrRateLimited)
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// DecodeError es un body que no se pudo decodificar
+type DecodeError struct {
+	What string // Qué se estaba decodificando
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("error decodificando %s: %v", e.What, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is permite errors.Is(err, ErrDecode)
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// RetriesError indica que se agotaron los reintentos. Err es el último error, así que
+// errors.Is/As también encuentran su clase (por ejemplo ErrUpstream o ErrRateLimited).
+type RetriesError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetriesError) Error() string {
+	return fmt.Sprintf("se agotaron los reintentos (%d intentos): %v", e.Attempts, e.Err)
+}
+
+func (e *RetriesError) Unwrap() error {
+	return e.Err
+}
+
+// Is permite errors.Is(err, ErrRetriesExhausted)
+func (e *RetriesError) Is(target error) bool {
+	return target == ErrRetriesExhausted
+}
+
+// checkStatus convierte el campo "status" del body en un error de la API
+func checkStatus(status int) error {
+	if status == http.StatusOK {
+		return nil
+	}
+	return &StatusError{StatusCode: status}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorClasses(t *testing.T) {
+	classes := []error{ErrNotFound, ErrUnauthorized, ErrRateLimited, ErrUpstream, ErrDecode, ErrRetriesExhausted}
+
+	tests := []struct {
+		name string
+		err  error
+		want []error // Clases que deben coincidir con errors.Is; el resto no
+	}{
+		{"404", &StatusError{StatusCode: 404}, []error{ErrNotFound}},
+		{"401", &StatusError{StatusCode: 401}, []error{ErrUnauthorized}},
+		{"403", &StatusError{StatusCode: 403}, []error{ErrUnauthorized}},
+		{"500", &StatusError{StatusCode: 500}, []error{ErrUpstream}},
+		{"503", &StatusError{StatusCode: 503}, []error{ErrUpstream}},
+		{"400 no tiene clase", &StatusError{StatusCode: 400}, nil},
+		{"408 no tiene clase", &StatusError{StatusCode: 408}, nil},
+		{"429", &RateLimitError{RetryAfter: time.Second}, []error{ErrRateLimited}},
+		{"decode", &DecodeError{What: "partida", Err: errors.New("eof")}, []error{ErrDecode}},
+		{"reintentos sobre 5xx", &RetriesError{Attempts: 3, Err: &StatusError{StatusCode: 502}}, []error{ErrRetriesExhausted, ErrUpstream}},
+		{"reintentos sobre 429", &RetriesError{Attempts: 3, Err: &RateLimitError{}}, []error{ErrRetriesExhausted, ErrRateLimited}},
+		{"envuelto con %w", fmt.Errorf("partida abc: %w", &StatusError{StatusCode: 404}), []error{ErrNotFound}},
+		{"checkStatus del body", checkStatus(404), []error{ErrNotFound}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, class := range classes {
+				want := false
+				for _, w := range tt.want {
+					want = want || w == class
+				}
+				if got := errors.Is(tt.err, class); got != want {
+					t.Errorf("errors.Is(%v, %q) = %v, se esperaba %v", tt.err, class, got, want)
+				}
+			}
+		})
+	}
+
+	if err := checkStatus(200); err != nil {
+		t.Errorf("checkStatus(200) = %v, se esperaba nil", err)
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	cause := errors.New("eof")
+	err := fmt.Errorf("lista: %w", &RetriesError{Attempts: 4, Err: &RateLimitError{RetryAfter: 3 * time.Second}})
+
+	var retriesErr *RetriesError
+	if !errors.As(err, &retriesErr) || retriesErr.Attempts != 4 {
+		t.Errorf("RetriesError = %+v, se esperaban 4 intentos", retriesErr)
+	}
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != 3*time.Second {
+		t.Errorf("RateLimitError = %+v, se esperaba RetryAfter de 3s", rateErr)
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		t.Errorf("errors.As encontró un StatusError en %v", err)
+	}
+
+	// DecodeError deja llegar a la causa original
+	if decodeErr := (&DecodeError{What: "partida", Err: cause}); !errors.Is(decodeErr, cause) {
+		t.Errorf("errors.Is(%v, causa) = false", decodeErr)
+	}
+}
+
+func TestStatusErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  *StatusError
+		want string
+	}{
+		{&StatusError{StatusCode: 404}, "HTTP 404"},
+		{&StatusError{StatusCode: 500, Body: "  caído \n"}, "HTTP 500: caído"},
+		{&StatusError{StatusCode: 502, Body: strings.Repeat("x", 300)}, "HTTP 502: " + strings.Repeat("x", 200) + "..."},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, se esperaba %q", got, tt.want)
+		}
+	}
+}
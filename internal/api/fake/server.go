@@ -0,0 +1,233 @@
+package fake
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"valo-track/internal/api"
+)
+
+// Options configura los fallos que simula el servidor
+type Options struct {
+	FixturesDir  string        // Directorio de fixtures (mismo formato que el modo replay)
+	APIKey       string        // Si no está vacío, exige el header Authorization
+	RateLimit    int           // Requests por minuto antes de responder 429 (0 = sin límite)
+	RetryAfter   int           // Segundos del header Retry-After (0 = calculado desde la ventana)
+	ErrorRate    float64       // Probabilidad de responder 5xx
+	NotFoundRate float64       // Probabilidad de responder 404 aunque exista el fixture
+	Latency      time.Duration // Demora fija por request
+	Jitter       time.Duration // Demora aleatoria adicional (0..Jitter)
+	Seed         int64         // Semilla para que los fallos sean reproducibles
+	Logger       *log.Logger   // Si no es nil, registra cada request
+}
+
+// Server imita los endpoints de HenrikDev que usa APIClient
+type Server struct {
+	opts   Options
+	replay *api.ReplayTransport
+
+	mu                sync.Mutex
+	rng               *rand.Rand
+	requestTimestamps []time.Time
+}
+
+// endpoints son los patrones de ruta (relativos a la versión) que sirve el servidor
+var endpoints = [][]string{
+	{"v1", "account", "*", "*", "*"},
+	{"v3", "by-puuid", "*", "*", "*"},
+	{"v4", "match", "*", "*"},
+	{"v2", "match", "*"},
+	{"v3", "matches", "*", "*", "*"},
+}
+
+// NewServer crea el servidor leyendo los fixtures de opts.FixturesDir
+func NewServer(opts Options) (*Server, error) {
+	replay, err := api.NewReplayTransport(opts.FixturesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &Server{
+		opts:   opts,
+		replay: replay,
+		rng:    rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// ServeHTTP aplica las fallas simuladas y responde desde los fixtures
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := s.serve(w, r)
+	if s.opts.Logger != nil {
+		s.opts.Logger.Printf("%s %s -> %d", r.Method, r.URL.Path, status)
+	}
+}
+
+// serve procesa la request y retorna el status enviado
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) int {
+	if r.Method != http.MethodGet {
+		return writeError(w, http.StatusMethodNotAllowed, "método no permitido")
+	}
+
+	if s.opts.APIKey != "" && r.Header.Get("Authorization") != s.opts.APIKey {
+		return writeError(w, http.StatusUnauthorized, "API key inválida")
+	}
+
+	if !isKnownEndpoint(api.FixtureKey(r.URL)) {
+		return writeError(w, http.StatusNotFound, "endpoint no soportado")
+	}
+
+	if delay := s.delay(); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return 499
+		}
+	}
+
+	if retryAfter, limited := s.takeToken(w); limited {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		return writeError(w, http.StatusTooManyRequests, "rate limit alcanzado")
+	}
+
+	if s.chance(s.opts.ErrorRate) {
+		codes := []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}
+		return writeError(w, codes[s.intn(len(codes))], "fallo simulado del servidor")
+	}
+
+	if s.chance(s.opts.NotFoundRate) {
+		return writeError(w, http.StatusNotFound, "recurso no encontrado (simulado)")
+	}
+
+	resp, err := s.replay.RoundTrip(r)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, err.Error())
+	}
+	defer resp.Body.Close()
+
+	for name := range resp.Header {
+		w.Header().Set(name, resp.Header.Get(name))
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+
+	return resp.StatusCode
+}
+
+// takeToken aplica la ventana deslizante de 60 segundos. Retorna los segundos de
+// espera y true si la request excede el límite.
+func (s *Server) takeToken(w http.ResponseWriter) (int, bool) {
+	if s.opts.RateLimit <= 0 {
+		return 0, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	oneMinuteAgo := now.Add(-time.Minute)
+
+	valid := s.requestTimestamps[:0]
+	for _, ts := range s.requestTimestamps {
+		if ts.After(oneMinuteAgo) {
+			valid = append(valid, ts)
+		}
+	}
+	s.requestTimestamps = valid
+
+	resetIn := 60
+	if len(s.requestTimestamps) > 0 {
+		resetIn = int(time.Until(s.requestTimestamps[0].Add(time.Minute)).Seconds()) + 1
+	}
+
+	w.Header().Set("X-Ratelimit-Limit", strconv.Itoa(s.opts.RateLimit))
+	w.Header().Set("X-Ratelimit-Reset", strconv.Itoa(resetIn))
+
+	if len(s.requestTimestamps) >= s.opts.RateLimit {
+		w.Header().Set("X-Ratelimit-Remaining", "0")
+		if s.opts.RetryAfter > 0 {
+			return s.opts.RetryAfter, true
+		}
+		return resetIn, true
+	}
+
+	s.requestTimestamps = append(s.requestTimestamps, now)
+	w.Header().Set("X-Ratelimit-Remaining", strconv.Itoa(s.opts.RateLimit-len(s.requestTimestamps)))
+
+	return 0, false
+}
+
+// delay calcula la demora simulada de la request
+func (s *Server) delay() time.Duration {
+	delay := s.opts.Latency
+	if s.opts.Jitter > 0 {
+		delay += time.Duration(s.int63n(int64(s.opts.Jitter)))
+	}
+	return delay
+}
+
+// chance retorna true con la probabilidad indicada
+func (s *Server) chance(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64() < probability
+}
+
+func (s *Server) intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Intn(n)
+}
+
+func (s *Server) int63n(n int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Int63n(n)
+}
+
+// isKnownEndpoint verifica si la ruta corresponde a un endpoint soportado
+func isKnownEndpoint(key string) bool {
+	segments := strings.Split(key, "/")
+	for _, endpoint := range endpoints {
+		if len(endpoint) != len(segments) {
+			continue
+		}
+		matches := true
+		for i, part := range endpoint {
+			if part != "*" && part != segments[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError responde con un error en el formato de HenrikDev
+func writeError(w http.ResponseWriter, status int, message string) int {
+	body := map[string]interface{}{
+		"status": status,
+		"errors": []map[string]string{{"message": message}},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+	return status
+}
@@ -0,0 +1,184 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"valo-track/internal/api"
+)
+
+// sampleID es el PUUID de match/account.json y el MatchID de las partidas de ejemplo
+const sampleID = "123e4567-e89b-12d3-a456-426614174000"
+
+// startServer levanta el servidor fake sobre los fixtures de match/. before, si no es nil,
+// puede responder una request antes que el servidor (retorna true si la respondió).
+// Retorna un cliente apuntando al servidor y el contador de requests recibidas.
+func startServer(t *testing.T, opts Options, maxRetries int, before func(w http.ResponseWriter, n int64) bool) (*api.APIClient, *int64) {
+	t.Helper()
+
+	opts.FixturesDir = "../../../match"
+	if opts.Seed == 0 {
+		opts.Seed = 1
+	}
+	server, err := NewServer(opts)
+	if err != nil {
+		t.Fatalf("error creando servidor: %v", err)
+	}
+
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&requests, 1)
+		if before != nil && before(w, n) {
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	client := api.NewAPIClient("key", "eu", 10*time.Second, maxRetries)
+	client.SetBaseURL(ts.URL)
+	return client, &requests
+}
+
+func TestRateLimitedThenRetried(t *testing.T) {
+	// La primera request recibe un 429 con Retry-After: 1; el reintento llega al servidor
+	client, requests := startServer(t, Options{}, 2, func(w http.ResponseWriter, n int64) bool {
+		if n > 1 {
+			return false
+		}
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return true
+	})
+
+	start := time.Now()
+	puuid, err := client.GetPlayerPUUID(context.Background(), "Henrik3", "EUW3")
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if puuid != sampleID {
+		t.Errorf("PUUID = %q, se esperaba %q", puuid, sampleID)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("el reintento llegó a los %s, antes del Retry-After de 1s", elapsed)
+	}
+	if got := atomic.LoadInt64(requests); got != 2 {
+		t.Errorf("requests = %d, se esperaban 2", got)
+	}
+}
+
+func TestRateLimitExhausted(t *testing.T) {
+	// Una request por minuto: después de la primera, todo es 429 hasta agotar reintentos
+	client, requests := startServer(t, Options{RateLimit: 1, RetryAfter: 1}, 1, nil)
+
+	if _, err := client.GetPlayerPUUID(context.Background(), "Henrik3", "EUW3"); err != nil {
+		t.Fatalf("primera request: %v", err)
+	}
+
+	_, err := client.GetPlayerPUUID(context.Background(), "Henrik3", "EUW3")
+	if !errors.Is(err, api.ErrRetriesExhausted) || !errors.Is(err, api.ErrRateLimited) {
+		t.Fatalf("error = %v, se esperaba ErrRetriesExhausted y ErrRateLimited", err)
+	}
+
+	var rateErr *api.RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != time.Second {
+		t.Errorf("RateLimitError = %+v, se esperaba RetryAfter de 1s", rateErr)
+	}
+	var retriesErr *api.RetriesError
+	if !errors.As(err, &retriesErr) || retriesErr.Attempts != 2 {
+		t.Errorf("RetriesError = %+v, se esperaban 2 intentos", retriesErr)
+	}
+	if got := atomic.LoadInt64(requests); got != 3 {
+		t.Errorf("requests = %d, se esperaban 3", got)
+	}
+}
+
+func TestUpstreamErrorsExhaustRetries(t *testing.T) {
+	client, requests := startServer(t, Options{ErrorRate: 1}, 2, nil)
+
+	_, err := client.GetMatchDetailsV4(context.Background(), sampleID)
+	if !errors.Is(err, api.ErrRetriesExhausted) || !errors.Is(err, api.ErrUpstream) {
+		t.Fatalf("error = %v, se esperaba ErrRetriesExhausted y ErrUpstream", err)
+	}
+
+	var statusErr *api.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode < 500 {
+		t.Errorf("StatusError = %+v, se esperaba un 5xx", statusErr)
+	}
+	if got := atomic.LoadInt64(requests); got != 3 {
+		t.Errorf("requests = %d, se esperaban 3 (1 + 2 reintentos)", got)
+	}
+}
+
+func TestNotFoundIsNotRetried(t *testing.T) {
+	client, requests := startServer(t, Options{NotFoundRate: 1}, 3, nil)
+
+	_, err := client.GetMatchDetailsV4(context.Background(), "no-existe")
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("error = %v, se esperaba ErrNotFound", err)
+	}
+	if errors.Is(err, api.ErrRetriesExhausted) {
+		t.Error("un 404 no debe reintentarse")
+	}
+
+	var statusErr *api.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusError = %+v, se esperaba 404", statusErr)
+	}
+	if got := atomic.LoadInt64(requests); got != 1 {
+		t.Errorf("requests = %d, se esperaba 1", got)
+	}
+}
+
+func TestLatencyCancelled(t *testing.T) {
+	client, _ := startServer(t, Options{Latency: 5 * time.Second}, 3, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetMatchDetailsV4(ctx, sampleID)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, se esperaba context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("la cancelación tardó %s, se esperaba que cortara la request en curso", elapsed)
+	}
+}
+
+func TestFixturesServed(t *testing.T) {
+	client, _ := startServer(t, Options{}, 0, nil)
+
+	match, err := client.GetMatchDetailsV4(context.Background(), sampleID)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if match.Data.Metadata.Map.Name != "Ascent" || len(match.Data.Players) != 1 {
+		t.Errorf("partida = %s con %d jugadores, se esperaba Ascent con 1",
+			match.Data.Metadata.Map.Name, len(match.Data.Players))
+	}
+
+	list, err := client.GetMatchList(context.Background(), "Henrik3", "EUW3", "competitive", 10)
+	if err != nil {
+		t.Fatalf("error en la lista: %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("partidas en la lista = %d, se esperaba 1", len(list))
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	client, requests := startServer(t, Options{APIKey: "otra-key"}, 3, nil)
+
+	_, err := client.GetPlayerPUUID(context.Background(), "Henrik3", "EUW3")
+	if !errors.Is(err, api.ErrUnauthorized) {
+		t.Fatalf("error = %v, se esperaba ErrUnauthorized", err)
+	}
+	if got := atomic.LoadInt64(requests); got != 1 {
+		t.Errorf("requests = %d, se esperaba 1", got)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// FetchedMatch es el resultado de descargar una partida con FetchMatches
+type FetchedMatch struct {
+	Index   int // Posición de la partida en la lista pedida
+	MatchID string
+	Match   *V4MatchResponse
+	Err     error
+}
+
+// FetchMatches descarga los detalles de las partidas con hasta workers requests en
+// paralelo y envía cada resultado apenas llega, en orden de llegada. El ritmo lo pone el
+// RateLimiter del cliente, así que más workers no exceden el cupo: solo aprovechan la
+// latencia. El canal se cierra cuando terminan todas las descargas o se cancela ctx
+// (el que consume debe cancelar ctx si deja de leer antes de tiempo).
+func (ac *APIClient) FetchMatches(ctx context.Context, matchIDs []string, workers int) <-chan FetchedMatch {
+	workers = max(1, min(workers, len(matchIDs)))
+
+	jobs := make(chan int)
+	results := make(chan FetchedMatch, workers)
+
+	// Repartir las partidas entre los workers
+	go func() {
+		defer close(jobs)
+		for i := range matchIDs {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				match, err := ac.GetMatchDetailsV4(ctx, matchIDs[i])
+				select {
+				case results <- FetchedMatch{Index: i, MatchID: matchIDs[i], Match: match, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"valo-track/internal/models"
+)
+
+// maxBackoff es la espera máxima entre reintentos
+const maxBackoff = 30 * time.Second
+
+// RateLimiter controla cuántas requests HTTP hace el cliente. Combina una ventana
+// deslizante local de 60 segundos con lo que informa la API en los headers
+// x-ratelimit-remaining / x-ratelimit-reset, y pausa a todos los que lo comparten
+// cuando la API responde 429.
+type RateLimiter struct {
+	mu          sync.Mutex
+	maxRequests int           // Máximo de requests por ventana (30)
+	window      time.Duration // Ventana deslizante (60 segundos)
+	timestamps  []time.Time   // Requests hechas dentro de la ventana
+
+	serverRemaining int       // Requests que la API dice que quedan (-1 = desconocido)
+	serverReset     time.Time // Cuándo la API renueva el cupo
+	blockedUntil    time.Time // Pausa global tras un 429
+
+	now func() time.Time // Reloj (se reemplaza en los tests)
+}
+
+// NewRateLimiter crea un limitador de maxRequests por minuto
+func NewRateLimiter(maxRequests int) *RateLimiter {
+	if maxRequests <= 0 {
+		maxRequests = 1
+	}
+	return &RateLimiter{
+		maxRequests:     maxRequests,
+		window:          time.Minute,
+		timestamps:      make([]time.Time, 0, maxRequests),
+		serverRemaining: -1,
+		now:             time.Now,
+	}
+}
+
+// Wait espera hasta que se pueda hacer una request y la registra. Retorna ctx.Err()
+// si se cancela mientras espera.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := rl.reserve(rl.now())
+		if wait <= 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve registra la request si hay cupo (retorna 0) o retorna cuánto esperar
+func (rl *RateLimiter) reserve(now time.Time) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if now.Before(rl.blockedUntil) {
+		return rl.blockedUntil.Sub(now)
+	}
+
+	// Cupo informado por la API: si se agotó, esperar al reset
+	if rl.serverRemaining >= 0 && !now.Before(rl.serverReset) {
+		rl.serverRemaining = -1
+	}
+	if rl.serverRemaining == 0 {
+		return rl.serverReset.Sub(now)
+	}
+
+	// Remover timestamps que están fuera de la ventana
+	rl.prune(now)
+	if len(rl.timestamps) >= rl.maxRequests {
+		return rl.timestamps[0].Add(rl.window).Sub(now)
+	}
+
+	rl.timestamps = append(rl.timestamps, now)
+	if rl.serverRemaining > 0 {
+		rl.serverRemaining--
+	}
+	return 0
+}
+
+// prune descarta los timestamps que salieron de la ventana (con el lock tomado)
+func (rl *RateLimiter) prune(now time.Time) {
+	start := now.Add(-rl.window)
+	valid := 0
+	for valid < len(rl.timestamps) && !rl.timestamps[valid].After(start) {
+		valid++
+	}
+	rl.timestamps = rl.timestamps[valid:]
+}
+
+// Observe ajusta el limitador con los headers x-ratelimit-* de una respuesta. La API
+// manda cuántas requests quedan y en cuántos segundos se renueva el cupo; si faltan,
+// solo cuenta la ventana local.
+func (rl *RateLimiter) Observe(header http.Header) {
+	remaining, okRemaining := headerInt(header, "X-Ratelimit-Remaining")
+	reset, okReset := headerInt(header, "X-Ratelimit-Reset")
+	if !okRemaining || !okReset {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.serverRemaining = max(remaining, 0)
+	rl.serverReset = rl.now().Add(time.Duration(max(reset, 0)) * time.Second)
+}
+
+// Pause bloquea todas las requests durante d (por ejemplo, tras un 429)
+func (rl *RateLimiter) Pause(d time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if until := rl.now().Add(d); until.After(rl.blockedUntil) {
+		rl.blockedUntil = until
+	}
+}
+
+// Status retorna el estado actual del limitador
+func (rl *RateLimiter) Status() *models.RateLimitStatus {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.prune(now)
+
+	made := len(rl.timestamps)
+	remaining := rl.maxRequests - made
+	resetTime := now.Add(rl.window).Unix()
+	if made > 0 {
+		resetTime = rl.timestamps[0].Add(rl.window).Unix()
+	}
+	if rl.serverRemaining >= 0 && now.Before(rl.serverReset) && rl.serverRemaining < remaining {
+		remaining = rl.serverRemaining
+		resetTime = rl.serverReset.Unix()
+	}
+
+	return &models.RateLimitStatus{
+		RequestsMade:      made,
+		RequestsRemaining: remaining,
+		ResetTime:         resetTime,
+		IsThrottled:       remaining <= 0 || now.Before(rl.blockedUntil),
+	}
+}
+
+// headerInt lee un header numérico
+func headerInt(header http.Header, name string) (int, bool) {
+	value, err := strconv.Atoi(strings.TrimSpace(header.Get(name)))
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// backoff retorna la espera antes del reintento attempt (1, 2, ...): crece de forma
+// exponencial desde base hasta maxBackoff, con jitter para que varios clientes no
+// reintenten todos a la vez (entre la mitad y el total del valor)
+func backoff(base time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, maxBackoff)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
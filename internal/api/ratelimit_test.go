@@ -0,0 +1,194 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2025, 3, 15, 18, 0, 0, 0, time.UTC)
+
+// testLimiter crea un limitador cuyo reloj se mueve a mano con el puntero retornado
+func testLimiter(maxRequests int) (*RateLimiter, *time.Time) {
+	clock := testStart
+	rl := NewRateLimiter(maxRequests)
+	rl.now = func() time.Time { return clock }
+	return rl, &clock
+}
+
+func rateHeaders(remaining, reset int) http.Header {
+	header := http.Header{}
+	header.Set("X-Ratelimit-Remaining", strconv.Itoa(remaining))
+	header.Set("X-Ratelimit-Reset", strconv.Itoa(reset))
+	return header
+}
+
+func TestRateLimiterWindow(t *testing.T) {
+	rl, _ := testLimiter(3)
+
+	for i := 0; i < 3; i++ {
+		if wait := rl.reserve(testStart.Add(time.Duration(i) * time.Second)); wait != 0 {
+			t.Fatalf("request %d: espera %s, se esperaba 0", i, wait)
+		}
+	}
+
+	// La cuarta espera a que la primera salga de la ventana
+	if wait := rl.reserve(testStart.Add(3 * time.Second)); wait != 57*time.Second {
+		t.Errorf("espera con la ventana llena = %s, se esperaba 57s", wait)
+	}
+
+	// A los 60s la primera sale de la ventana (y solo la primera)
+	if wait := rl.reserve(testStart.Add(time.Minute)); wait != 0 {
+		t.Errorf("espera al expirar la primera = %s, se esperaba 0", wait)
+	}
+	if wait := rl.reserve(testStart.Add(time.Minute)); wait != time.Second {
+		t.Errorf("espera tras reusar el lugar = %s, se esperaba 1s", wait)
+	}
+	if got := len(rl.timestamps); got != 3 {
+		t.Errorf("timestamps en la ventana = %d, se esperaba 3", got)
+	}
+}
+
+func TestRateLimiterServerRemaining(t *testing.T) {
+	rl, clock := testLimiter(30)
+
+	// La API dice que quedan 2 requests y que el cupo se renueva en 10 segundos
+	rl.Observe(rateHeaders(2, 10))
+	for i := 0; i < 2; i++ {
+		if wait := rl.reserve(*clock); wait != 0 {
+			t.Fatalf("request %d: espera %s, se esperaba 0", i, wait)
+		}
+	}
+	if wait := rl.reserve(testStart.Add(4 * time.Second)); wait != 6*time.Second {
+		t.Errorf("espera con el cupo agotado = %s, se esperaba 6s", wait)
+	}
+	if status := rl.Status(); status.RequestsRemaining != 0 || !status.IsThrottled {
+		t.Errorf("Status() = %+v, se esperaba 0 restantes y throttled", status)
+	}
+
+	// Tras el reset vuelve a contar solo la ventana local
+	if wait := rl.reserve(testStart.Add(10 * time.Second)); wait != 0 {
+		t.Errorf("espera tras el reset = %s, se esperaba 0", wait)
+	}
+	if rl.serverRemaining != -1 {
+		t.Errorf("serverRemaining = %d, se esperaba -1 (desconocido)", rl.serverRemaining)
+	}
+}
+
+func TestRateLimiterObserveZero(t *testing.T) {
+	rl, _ := testLimiter(30)
+
+	rl.Observe(rateHeaders(0, 15))
+	if wait := rl.reserve(testStart); wait != 15*time.Second {
+		t.Errorf("espera con remaining=0 = %s, se esperaba 15s", wait)
+	}
+
+	// Valores negativos se toman como 0
+	rl.Observe(rateHeaders(-3, -1))
+	if wait := rl.reserve(testStart); wait != 0 {
+		t.Errorf("espera con reset vencido = %s, se esperaba 0", wait)
+	}
+}
+
+func TestRateLimiterObserveMissingHeaders(t *testing.T) {
+	rl, _ := testLimiter(30)
+
+	header := http.Header{}
+	header.Set("X-Ratelimit-Remaining", "0") // Sin reset no se sabe hasta cuándo esperar
+	rl.Observe(header)
+	rl.Observe(http.Header{})
+
+	if wait := rl.reserve(testStart); wait != 0 {
+		t.Errorf("espera sin headers completos = %s, se esperaba 0", wait)
+	}
+	if rl.serverRemaining != -1 {
+		t.Errorf("serverRemaining = %d, se esperaba -1", rl.serverRemaining)
+	}
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	rl, clock := testLimiter(30)
+
+	rl.Pause(5 * time.Second)
+	if wait := rl.reserve(testStart.Add(time.Second)); wait != 4*time.Second {
+		t.Errorf("espera durante la pausa = %s, se esperaba 4s", wait)
+	}
+	if !rl.Status().IsThrottled {
+		t.Error("Status().IsThrottled = false durante la pausa")
+	}
+
+	// Una pausa más corta no acorta la vigente
+	*clock = testStart.Add(time.Second)
+	rl.Pause(time.Second)
+	if wait := rl.reserve(testStart.Add(3 * time.Second)); wait != 2*time.Second {
+		t.Errorf("espera tras una pausa más corta = %s, se esperaba 2s", wait)
+	}
+
+	if wait := rl.reserve(testStart.Add(5 * time.Second)); wait != 0 {
+		t.Errorf("espera al terminar la pausa = %s, se esperaba 0", wait)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base := 500 * time.Millisecond
+
+	tests := []struct {
+		attempt int
+		want    time.Duration // Tope antes del jitter
+	}{
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{6, 16 * time.Second},
+		{7, maxBackoff},
+		{50, maxBackoff},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 200; i++ {
+			got := backoff(base, tt.attempt)
+			if got < tt.want/2 || got > tt.want {
+				t.Fatalf("backoff(%s, %d) = %s, fuera de [%s, %s]", base, tt.attempt, got, tt.want/2, tt.want)
+			}
+		}
+	}
+
+	if got := backoff(0, 3); got != 0 {
+		t.Errorf("backoff(0, 3) = %s, se esperaba 0", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := func(pairs ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i+1 < len(pairs); i += 2 {
+			h.Set(pairs[i], pairs[i+1])
+		}
+		return h
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"segundos", header("Retry-After", "12"), 12 * time.Second},
+		{"fecha HTTP", header("Retry-After", testStart.Add(90*time.Second).Format(http.TimeFormat)), 90 * time.Second},
+		{"fecha pasada usa el reset", header("Retry-After", testStart.Add(-time.Minute).Format(http.TimeFormat), "X-Ratelimit-Reset", "7"), 7 * time.Second},
+		{"negativo usa el reset", header("Retry-After", "-5", "X-Ratelimit-Reset", "7"), 7 * time.Second},
+		{"inválido usa el reset", header("Retry-After", "pronto", "X-Ratelimit-Reset", "7"), 7 * time.Second},
+		{"solo reset", header("X-Ratelimit-Reset", "20"), 20 * time.Second},
+		{"Retry-After tiene prioridad", header("Retry-After", "3", "X-Ratelimit-Reset", "20"), 3 * time.Second},
+		{"sin headers", header(), 0},
+		{"reset en 0", header("X-Ratelimit-Reset", "0"), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.header, testStart); got != tt.want {
+				t.Errorf("retryAfter() = %s, se esperaba %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Modos de transporte del cliente
+const (
+	ModeLive   = "live"   // Requests reales a la API
+	ModeRecord = "record" // Requests reales, guardando cada respuesta como fixture
+	ModeReplay = "replay" // Sin red: responde desde los fixtures guardados
+)
+
+// fixtureManifest es el archivo opcional que mapea patrones de rutas a fixtures
+const fixtureManifest = "fixtures.json"
+
+// Headers que se conservan al grabar una respuesta
+var recordedHeaders = []string{"Content-Type", "Retry-After", "X-Ratelimit-Limit", "X-Ratelimit-Remaining", "X-Ratelimit-Reset"}
+
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// fixtureMeta guarda el status y los headers relevantes de una respuesta grabada
+type fixtureMeta struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// NewTransport crea el http.RoundTripper correspondiente al modo indicado
+func NewTransport(mode, fixturesDir string) (http.RoundTripper, error) {
+	switch mode {
+	case "", ModeLive:
+		return http.DefaultTransport, nil
+	case ModeRecord:
+		return NewRecordTransport(fixturesDir, http.DefaultTransport)
+	case ModeReplay:
+		return NewReplayTransport(fixturesDir)
+	default:
+		return nil, fmt.Errorf("modo de API desconocido %q (usar %s, %s o %s)", mode, ModeLive, ModeRecord, ModeReplay)
+	}
+}
+
+// RecordTransport ejecuta las requests con el transporte real y guarda cada respuesta
+type RecordTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// NewRecordTransport crea un transporte que graba las respuestas en dir
+func NewRecordTransport(dir string, next http.RoundTripper) (*RecordTransport, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de fixtures: %w", err)
+	}
+	return &RecordTransport{dir: dir, next: next}, nil
+}
+
+// RoundTrip ejecuta la request y graba body, status y headers
+func (rt *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	meta := fixtureMeta{Status: resp.StatusCode, Headers: make(map[string]string)}
+	for _, name := range recordedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			meta.Headers[name] = value
+		}
+	}
+
+	if err := rt.save(FixtureKey(req.URL), body, meta); err != nil {
+		fmt.Printf("  ⚠️  No se pudo grabar fixture de %s: %v\n", req.URL.Path, err)
+	}
+
+	return resp, nil
+}
+
+// save escribe el body y su metadata en el directorio de fixtures
+func (rt *RecordTransport) save(key string, body []byte, meta fixtureMeta) error {
+	bodyPath := filepath.Join(rt.dir, filepath.FromSlash(key)+".json")
+	if err := os.MkdirAll(filepath.Dir(bodyPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(bodyPath, body, 0644); err != nil {
+		return err
+	}
+
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(strings.TrimSuffix(bodyPath, ".json")+".meta.json", metaData, 0644)
+}
+
+// ReplayTransport responde las requests desde los fixtures, sin acceder a la red.
+// Busca primero el fixture grabado para la ruta exacta y luego los patrones de fixtures.json.
+type ReplayTransport struct {
+	dir      string
+	patterns []fixturePattern
+}
+
+// fixturePattern asocia un patrón de ruta (path.Match) a un archivo de fixture
+type fixturePattern struct {
+	Pattern string
+	File    string
+}
+
+// NewReplayTransport crea un transporte que lee los fixtures de dir
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo directorio de fixtures: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s no es un directorio", dir)
+	}
+
+	rt := &ReplayTransport{dir: dir}
+	if err := rt.loadManifest(); err != nil {
+		return nil, err
+	}
+
+	return rt, nil
+}
+
+// loadManifest lee fixtures.json si existe. El formato es una lista ordenada de
+// {"pattern": "v4/match/*/*", "file": "region.json"}; gana el primer patrón que coincide.
+func (rt *ReplayTransport) loadManifest() error {
+	data, err := os.ReadFile(filepath.Join(rt.dir, fixtureManifest))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries []struct {
+		Pattern string `json:"pattern"`
+		File    string `json:"file"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("error decodificando %s: %w", fixtureManifest, err)
+	}
+
+	for _, entry := range entries {
+		if _, err := path.Match(entry.Pattern, ""); err != nil {
+			return fmt.Errorf("patrón inválido en %s: %q", fixtureManifest, entry.Pattern)
+		}
+		rt.patterns = append(rt.patterns, fixturePattern{Pattern: entry.Pattern, File: entry.File})
+	}
+
+	return nil
+}
+
+// RoundTrip responde con el fixture de la request o con un 404 si no existe
+func (rt *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := FixtureKey(req.URL)
+	bodyPath, meta, err := rt.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	if bodyPath == "" {
+		body := fmt.Sprintf(`{"status":404,"errors":[{"message":"fixture no encontrado para %s"}]}`, key)
+		return newReplayResponse(req, http.StatusNotFound, nil, []byte(body)), nil
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo fixture %s: %w", bodyPath, err)
+	}
+
+	return newReplayResponse(req, meta.Status, meta.Headers, body), nil
+}
+
+// resolve busca el archivo de fixture para una clave. Un .meta.json ilegible es un
+// error: responder con un status inventado escondería el fixture roto.
+func (rt *ReplayTransport) resolve(key string) (string, fixtureMeta, error) {
+	meta := fixtureMeta{Status: http.StatusOK}
+
+	recorded := filepath.Join(rt.dir, filepath.FromSlash(key))
+	if _, err := os.Stat(recorded + ".json"); err == nil {
+		data, err := os.ReadFile(recorded + ".meta.json")
+		if err != nil && !os.IsNotExist(err) {
+			return "", meta, fmt.Errorf("error leyendo metadata del fixture %s: %w", key, err)
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &meta); err != nil {
+				return "", meta, fmt.Errorf("error decodificando metadata del fixture %s: %w", key, err)
+			}
+			if meta.Status == 0 {
+				return "", meta, fmt.Errorf("metadata del fixture %s sin status válido", key)
+			}
+		}
+		return recorded + ".json", meta, nil
+	}
+
+	for _, pattern := range rt.patterns {
+		if ok, _ := path.Match(pattern.Pattern, key); ok {
+			return filepath.Join(rt.dir, pattern.File), meta, nil
+		}
+	}
+
+	return "", meta, nil
+}
+
+// newReplayResponse construye una respuesta HTTP en memoria
+func newReplayResponse(req *http.Request, status int, headers map[string]string, body []byte) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// FixtureKey convierte la URL de una request en la ruta relativa de su fixture.
+// Se descarta todo lo anterior al segmento de versión (v1, v3, v4...) para que
+// los fixtures no dependan de la URL base, y cada segmento se escapa para poder
+// usarse como nombre de archivo (Riot IDs con espacios, # o unicode). La query,
+// con los parámetros ordenados, se agrega escapada al último segmento: requests
+// que solo difieren en sus parámetros no comparten fixture.
+func FixtureKey(u *url.URL) string {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, segment := range segments {
+		if versionSegment.MatchString(segment) {
+			segments = segments[i:]
+			break
+		}
+	}
+
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	key := strings.Join(segments, "/")
+	if query := u.Query().Encode(); query != "" {
+		key += url.PathEscape("?" + query)
+	}
+	return key
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtureKey(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.henrikdev.xyz/valorant/v4/match/na/abc", "v4/match/na/abc"},
+		{"http://localhost:8089/v1/account/na/Ros%20arino/CARC", "v1/account/na/Ros%20arino/CARC"},
+		{"http://localhost/valorant/v1/account/na/A%23B/1", "v1/account/na/A%23B/1"},
+		// Los parámetros se ordenan: el orden en la URL no cambia el fixture
+		{"https://x/valorant/v3/matches/na/R/C?size=10&mode=competitive", "v3/matches/na/R/C%3Fmode=competitive&size=10"},
+		{"https://x/valorant/v3/matches/na/R/C?mode=competitive&size=10", "v3/matches/na/R/C%3Fmode=competitive&size=10"},
+		{"https://x/valorant/v3/matches/na/R/C?mode=competitive&size=5", "v3/matches/na/R/C%3Fmode=competitive&size=5"},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FixtureKey(u); got != tt.want {
+			t.Errorf("FixtureKey(%s) = %q, se esperaba %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+// replayGet hace un GET contra el transporte de replay y retorna status y body
+func replayGet(t *testing.T, rt http.RoundTripper, rawURL string) (int, string, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), nil
+}
+
+func TestReplayTransport(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("fixtures.json", `[{"pattern": "v3/matches/*/*/*", "file": "list.json"}]`)
+	write("list.json", `"patrón"`)
+	write("v3/matches/na/R/C%3Fmode=competitive&size=5.json", `"size 5"`)
+	write("v4/match/na/ok.json", `"ok"`)
+	write("v4/match/na/ok.meta.json", `{"status": 429, "headers": {"Retry-After": "3"}}`)
+	write("v4/match/na/sinmeta.json", `"sin meta"`)
+	write("v4/match/na/roto.json", `"roto"`)
+	write("v4/match/na/roto.meta.json", `{"status": `)
+	write("v4/match/na/vacio.json", `"vacío"`)
+	write("v4/match/na/vacio.meta.json", `{"status": 0}`)
+
+	rt, err := NewReplayTransport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantBody   string
+		wantErr    string
+	}{
+		{name: "status grabado", url: "https://x/v4/match/na/ok", wantStatus: 429, wantBody: `"ok"`},
+		{name: "sin metadata es 200", url: "https://x/v4/match/na/sinmeta", wantStatus: 200, wantBody: `"sin meta"`},
+		{name: "query grabada", url: "https://x/v3/matches/na/R/C?size=5&mode=competitive", wantStatus: 200, wantBody: `"size 5"`},
+		{name: "otra query cae en el patrón", url: "https://x/v3/matches/na/R/C?mode=competitive&size=10", wantStatus: 200, wantBody: `"patrón"`},
+		{name: "sin fixture", url: "https://x/v4/match/na/otra", wantStatus: 404},
+		{name: "metadata ilegible", url: "https://x/v4/match/na/roto", wantErr: "decodificando metadata"},
+		{name: "metadata con status 0", url: "https://x/v4/match/na/vacio", wantErr: "sin status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body, err := replayGet(t, rt, tt.url)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, se esperaba %d", status, tt.wantStatus)
+			}
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("body = %s, se esperaba %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// memArchive guarda en memoria los bodies archivados por MatchID
+type memArchive map[string][]byte
+
+func (ma memArchive) SaveRaw(matchID string, body []byte) error {
+	ma[matchID] = body
+	return nil
+}
+
+func TestReplayArchivesByResponseID(t *testing.T) {
+	rt, err := NewReplayTransport("../../match")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive := memArchive{}
+	client := NewAPIClient("", "eu", 0, 0)
+	client.SetTransport(rt)
+	client.SetArchive(archive)
+
+	// El mismo fixture (region.json) responde a las dos partidas
+	for _, matchID := range []string{"partida-a", "partida-b"} {
+		if _, err := client.GetMatchDetailsV4(context.Background(), matchID); err != nil {
+			t.Fatalf("error en %s: %v", matchID, err)
+		}
+	}
+	if _, err := client.GetMatchDetailsV2(context.Background(), "partida-c"); err != nil {
+		t.Fatalf("error en v2: %v", err)
+	}
+
+	if len(archive) != 1 || archive[sampleID] == nil {
+		ids := make([]string, 0, len(archive))
+		for id := range archive {
+			ids = append(ids, id)
+		}
+		t.Errorf("archivadas = %v, se esperaba solo %s", ids, sampleID)
+	}
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"valo-track/internal/models"
+)
+
+// Alias para las respuestas v2
+type V2MatchResponse = models.V2MatchResponse
+type V2Match = models.V2Match
+
+// DecodeMatch decodifica una partida en formato v2 o v4 (se detecta por la forma de
+// "players": objeto en v2, lista en v4) y la retorna normalizada a v4
+func DecodeMatch(body []byte) (*V4MatchResponse, error) {
+	var probe struct {
+		Data struct {
+			Players json.RawMessage `json:"players"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, &DecodeError{What: "partida", Err: err}
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(probe.Data.Players), []byte("{")) {
+		return DecodeMatchV2(body)
+	}
+	return DecodeMatchV4(body)
+}
+
+// DecodeMatchV2 decodifica el body de una respuesta v2/match y lo convierte a v4
+func DecodeMatchV2(body []byte) (*V4MatchResponse, error) {
+	var response V2MatchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, &DecodeError{What: "match details v2", Err: err}
+	}
+
+	if err := checkStatus(response.Status); err != nil {
+		return nil, err
+	}
+
+	return ConvertMatchV2(&response.Data), nil
+}
+
+// SplitMatches separa un dump en los bodies de cada partida. Acepta la respuesta de una
+// partida (v2 o v4, se retorna tal cual) o una lista como la de v3/matches, cuyas partidas
+// se envuelven como respuestas individuales para poder archivarlas y leerlas con DecodeMatch.
+func SplitMatches(body []byte) ([][]byte, error) {
+	var probe struct {
+		Status int             `json:"status"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, &DecodeError{What: "dump de partidas", Err: err}
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(probe.Data), []byte("[")) {
+		return [][]byte{body}, nil
+	}
+
+	if err := checkStatus(probe.Status); err != nil {
+		return nil, err
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(probe.Data, &items); err != nil {
+		return nil, &DecodeError{What: "lista de partidas", Err: err}
+	}
+
+	bodies := make([][]byte, 0, len(items))
+	for _, item := range items {
+		single, err := json.Marshal(struct {
+			Status int             `json:"status"`
+			Data   json.RawMessage `json:"data"`
+		}{200, item})
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, single)
+	}
+	return bodies, nil
+}
+
+// ConvertMatchV2 lleva una partida v2 al formato v4 que usa el análisis. v2 no trae el
+// nombre corto de la temporada, solo su UUID: Season.Short queda vacío y se completa
+// desde otras partidas de la misma season (ver SyncFromMatchList y Timeframe.Apply). Las kills salen
+// de las kill_events de cada ronda porque la lista global no indica la ronda.
+func ConvertMatchV2(match *V2Match) *V4MatchResponse {
+	response := &V4MatchResponse{Status: 200}
+	data := &response.Data
+
+	data.ID = match.Metadata.MatchID
+	data.Metadata.MatchID = match.Metadata.MatchID
+	data.Metadata.Map.Name = match.Metadata.Map
+	data.Metadata.Queue.ID = match.Metadata.ModeID
+	if data.Metadata.Queue.ID == "" {
+		data.Metadata.Queue.ID = strings.ToLower(match.Metadata.Mode)
+	}
+	data.Metadata.Region = match.Metadata.Region
+	data.Metadata.GameStart = match.Metadata.GameStart
+	data.Metadata.Season.ID = match.Metadata.SeasonID
+
+	for _, p := range match.Players.AllPlayers {
+		player := models.V4MatchPlayer{
+			PUUID:  p.PUUID,
+			Name:   p.Name,
+			Tag:    p.Tag,
+			TeamID: p.Team,
+		}
+		player.Agent.Name = p.Character
+		player.Stats.Score = p.Stats.Score
+		player.Stats.Kills = p.Stats.Kills
+		player.Stats.Deaths = p.Stats.Deaths
+		player.Stats.Assists = p.Stats.Assists
+		player.Stats.Headshots = p.Stats.Headshots
+		player.Stats.Bodyshots = p.Stats.Bodyshots
+		player.Stats.Legshots = p.Stats.Legshots
+		player.Stats.Damage.Dealt = p.DamageMade
+		player.Stats.Damage.Received = p.DamageReceived
+		data.Players = append(data.Players, player)
+	}
+
+	// Los equipos vienen como mapa ("red", "blue"): ordenarlos para que el resultado sea estable
+	teamKeys := make([]string, 0, len(match.Teams))
+	for key := range match.Teams {
+		teamKeys = append(teamKeys, key)
+	}
+	sort.Strings(teamKeys)
+	for _, key := range teamKeys {
+		v2Team := match.Teams[key]
+		team := models.V4Team{TeamID: teamID(key), Won: v2Team.HasWon != nil && *v2Team.HasWon}
+		team.Rounds.Won = v2Team.RoundsWon
+		team.Rounds.Lost = v2Team.RoundsLost
+		data.Teams = append(data.Teams, team)
+	}
+
+	for i, v2Round := range match.Rounds {
+		round, kills := convertRoundV2(i, v2Round)
+		data.Rounds = append(data.Rounds, round)
+		data.Kills = append(data.Kills, kills...)
+	}
+
+	return response
+}
+
+// convertRoundV2 convierte una ronda v2 y retorna también sus kills. v2 no trae asistencias
+// por ronda en player_stats, así que se cuentan desde las kills.
+func convertRoundV2(index int, v2Round models.V2Round) (models.V4Round, []models.V4KillEventResponse) {
+	round := models.V4Round{
+		ID:          index,
+		Result:      v2Round.EndType,
+		WinningTeam: v2Round.WinningTeam,
+	}
+
+	if v2Round.BombPlanted && v2Round.PlantEvents.PlantedBy != nil {
+		round.Plant = &models.V4Plant{
+			RoundTimeInMs: v2Round.PlantEvents.PlantTimeInRound,
+			Site:          v2Round.PlantEvents.PlantSite,
+			Player:        playerRef(v2Round.PlantEvents.PlantedBy.PUUID, v2Round.PlantEvents.PlantedBy.DisplayName, v2Round.PlantEvents.PlantedBy.Team),
+		}
+	}
+	if v2Round.BombDefused && v2Round.DefuseEvents.DefusedBy != nil {
+		round.Defuse = &models.V4Defuse{
+			RoundTimeInMs: v2Round.DefuseEvents.DefuseTimeInRound,
+			Player:        playerRef(v2Round.DefuseEvents.DefusedBy.PUUID, v2Round.DefuseEvents.DefusedBy.DisplayName, v2Round.DefuseEvents.DefusedBy.Team),
+		}
+	}
+
+	var kills []models.V4KillEventResponse
+	assists := make(map[string]int)
+	for _, stats := range v2Round.PlayerStats {
+		for _, event := range stats.KillEvents {
+			kill := convertKillV2(index, event)
+			for _, assistant := range kill.Assistants {
+				assists[assistant.PUUID]++
+			}
+			kills = append(kills, kill)
+		}
+	}
+	sort.SliceStable(kills, func(i, j int) bool {
+		return kills[i].TimeInRoundInMs < kills[j].TimeInRoundInMs
+	})
+
+	for _, stats := range v2Round.PlayerStats {
+		entry := models.V4RoundStatsEntry{
+			Player: playerRef(stats.PlayerPUUID, stats.PlayerDisplayName, stats.PlayerTeam),
+		}
+		entry.Stats.Damage = stats.Damage
+		entry.Stats.Kills = stats.Kills
+		entry.Stats.Assists = assists[stats.PlayerPUUID]
+		entry.Stats.Score = stats.Score
+		entry.Stats.Headshots = stats.Headshots
+		entry.Stats.Bodyshots = stats.Bodyshots
+		entry.Stats.Legshots = stats.Legshots
+		if stats.Economy != nil {
+			entry.Economy = &models.V4Economy{
+				LoadoutValue: stats.Economy.LoadoutValue,
+				Spent:        stats.Economy.Spent,
+				Remaining:    stats.Economy.Remaining,
+				Weapon:       stats.Economy.Weapon,
+				Armor:        stats.Economy.Armor,
+			}
+		}
+		round.Stats = append(round.Stats, entry)
+	}
+
+	return round, kills
+}
+
+// convertKillV2 convierte una kill v2 de la ronda index
+func convertKillV2(index int, event models.V2KillEvent) models.V4KillEventResponse {
+	kill := models.V4KillEventResponse{
+		Round:           index,
+		TimeInRoundInMs: event.KillTimeInRound,
+		Killer:          playerRef(event.KillerPUUID, event.KillerDisplayName, event.KillerTeam),
+		Victim:          playerRef(event.VictimPUUID, event.VictimDisplayName, event.VictimTeam),
+		Weapon:          models.V4Item{ID: event.WeaponID, Name: event.WeaponName},
+		Location:        event.VictimLocation,
+	}
+
+	// v2 no indica el tipo: sin nombre de arma es una habilidad
+	switch {
+	case event.WeaponName != "":
+		kill.Weapon.Type = "Weapon"
+	case event.WeaponID != "":
+		kill.Weapon.Type = "Ability"
+	}
+
+	for _, assistant := range event.Assistants {
+		kill.Assistants = append(kill.Assistants, playerRef(assistant.PUUID, assistant.DisplayName, assistant.Team))
+	}
+
+	for _, position := range event.PlayerLocations {
+		kill.PlayerLocations = append(kill.PlayerLocations, models.V4PlayerLocation{
+			Player:      models.V4PlayerRef{PUUID: position.PlayerPUUID, Team: position.PlayerTeam},
+			ViewRadians: position.ViewRadians,
+			Location:    position.Location,
+		})
+	}
+
+	return kill
+}
+
+// playerRef arma la referencia a un jugador desde el display name "Nombre#Tag" de v2
+func playerRef(puuid, displayName, team string) models.V4PlayerRef {
+	name, tag, _ := strings.Cut(displayName, "#")
+	return models.V4PlayerRef{PUUID: puuid, Name: name, Tag: tag, Team: team}
+}
+
+// teamID convierte la clave de equipo de v2 ("red") al TeamID de v4 ("Red")
+func teamID(key string) string {
+	if key == "" {
+		return ""
+	}
+	return strings.ToUpper(key[:1]) + strings.ToLower(key[1:])
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"os"
+	"testing"
+	"valo-track/internal/models"
+)
+
+const (
+	sampleID    = "123e4567-e89b-12d3-a456-426614174000"
+	samplePUUID = "54942ced-1967-5f66-8a16-1e0dae875641"
+)
+
+func readSample(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile("../../match/" + name)
+	if err != nil {
+		t.Fatalf("leyendo %s: %v", name, err)
+	}
+	return body
+}
+
+// checkSampleV2 verifica la partida de ejemplo de match/match.json ya convertida a v4
+func checkSampleV2(t *testing.T, match *V4MatchResponse) {
+	t.Helper()
+	data := match.Data
+	henrik := models.V4PlayerRef{PUUID: samplePUUID, Name: "Henrik3", Tag: "EUW3", Team: "Red"}
+
+	if data.ID != sampleID || data.Metadata.MatchID != sampleID {
+		t.Errorf("ID = %q / %q, se esperaba %q", data.ID, data.Metadata.MatchID, sampleID)
+	}
+	if data.Metadata.Map.Name != "Ascent" || data.Metadata.Queue.ID != "competitive" || data.Metadata.Region != "eu" {
+		t.Errorf("metadata = %+v", data.Metadata)
+	}
+	if data.Metadata.GameStart != 1641934366 {
+		t.Errorf("GameStart = %d, se esperaba 1641934366", data.Metadata.GameStart)
+	}
+	if data.Metadata.Season.ID != sampleID || data.Metadata.Season.Short != "" {
+		t.Errorf("Season = %+v, se esperaba solo el ID %q", data.Metadata.Season, sampleID)
+	}
+
+	if len(data.Players) != 1 {
+		t.Fatalf("jugadores = %d, se esperaba 1", len(data.Players))
+	}
+	player := data.Players[0]
+	if player.PUUID != samplePUUID || player.Name != "Henrik3" || player.Tag != "EUW3" ||
+		player.TeamID != "Red" || player.Agent.Name != "Sova" {
+		t.Errorf("jugador = %+v", player)
+	}
+	stats := player.Stats
+	if stats.Score != 4869 || stats.Kills != 18 || stats.Deaths != 18 || stats.Assists != 5 ||
+		stats.Headshots != 9 || stats.Bodyshots != 48 || stats.Legshots != 5 ||
+		stats.Damage.Dealt != 3067 || stats.Damage.Received != 3115 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if len(data.Teams) != 2 {
+		t.Fatalf("equipos = %d, se esperaban 2", len(data.Teams))
+	}
+	for i, want := range []string{"Blue", "Red"} {
+		team := data.Teams[i]
+		if team.TeamID != want || !team.Won || team.Rounds.Won != 13 || team.Rounds.Lost != 10 {
+			t.Errorf("equipo %d = %+v, se esperaba %s ganador 13-10", i, team, want)
+		}
+	}
+
+	if len(data.Rounds) != 1 {
+		t.Fatalf("rondas = %d, se esperaba 1", len(data.Rounds))
+	}
+	round := data.Rounds[0]
+	if round.ID != 0 || round.WinningTeam != "Red" || round.Result != "Eliminated" {
+		t.Errorf("ronda = %+v", round)
+	}
+	if round.Plant == nil || round.Plant.Site != "A" || round.Plant.RoundTimeInMs != 26345 || round.Plant.Player != henrik {
+		t.Errorf("plant = %+v", round.Plant)
+	}
+	if round.Defuse != nil {
+		t.Errorf("defuse = %+v, se esperaba nil (bomb_defused es false)", round.Defuse)
+	}
+
+	if len(round.Stats) != 1 {
+		t.Fatalf("stats de ronda = %d, se esperaba 1", len(round.Stats))
+	}
+	entry := round.Stats[0]
+	if entry.Player != henrik || entry.Stats.Damage != 282 || entry.Stats.Kills != 2 || entry.Stats.Score != 430 ||
+		entry.Stats.Headshots != 1 || entry.Stats.Bodyshots != 7 || entry.Stats.Legshots != 1 {
+		t.Errorf("stats de ronda = %+v", entry)
+	}
+	// La asistencia de la ronda sale de las kills
+	if entry.Stats.Assists != 1 {
+		t.Errorf("asistencias de ronda = %d, se esperaba 1", entry.Stats.Assists)
+	}
+
+	economy := entry.Economy
+	if economy == nil {
+		t.Fatal("economía = nil")
+	}
+	if economy.LoadoutValue != 3900 || economy.Spent != 1550 || economy.Remaining != 5300 {
+		t.Errorf("economía = %+v", economy)
+	}
+	if economy.Weapon == nil || economy.Weapon.Name != "Spectre" || economy.Weapon.ID != "462080D1-4035-2937-7C09-27AA2A5C27A7" {
+		t.Errorf("arma comprada = %+v", economy.Weapon)
+	}
+	if economy.Armor == nil || economy.Armor.Name != "Heavy Shields" || economy.Armor.ID != "822BCAB2-40A2-324E-C137-E09195AD7692" {
+		t.Errorf("escudo = %+v", economy.Armor)
+	}
+
+	if len(data.Kills) != 1 {
+		t.Fatalf("kills = %d, se esperaba 1", len(data.Kills))
+	}
+	kill := data.Kills[0]
+	if kill.Round != 0 || kill.TimeInRoundInMs != 43163 || kill.Killer != henrik || kill.Victim != henrik {
+		t.Errorf("kill = %+v", kill)
+	}
+	if kill.Weapon != (models.V4Item{ID: "9C82E19D-4575-0200-1A81-3EACF00CF872", Name: "Vandal", Type: "Weapon"}) {
+		t.Errorf("arma de la kill = %+v", kill.Weapon)
+	}
+	if kill.Location == nil || *kill.Location != (models.Location{X: 7266, Y: -5096}) {
+		t.Errorf("ubicación de la víctima = %+v", kill.Location)
+	}
+	if len(kill.Assistants) != 1 || kill.Assistants[0] != henrik {
+		t.Errorf("asistentes = %+v", kill.Assistants)
+	}
+	if len(kill.PlayerLocations) != 1 || kill.PlayerLocations[0].Player.PUUID != samplePUUID ||
+		kill.PlayerLocations[0].Location != (models.Location{X: 5177, Y: -8908}) {
+		t.Errorf("posiciones = %+v", kill.PlayerLocations)
+	}
+}
+
+func TestDecodeMatchV2Sample(t *testing.T) {
+	match, err := DecodeMatch(readSample(t, "match.json"))
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	checkSampleV2(t, match)
+}
+
+func TestSplitMatchesV2Sample(t *testing.T) {
+	bodies, err := SplitMatches(readSample(t, "matches.json"))
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("partidas = %d, se esperaba 1", len(bodies))
+	}
+
+	match, err := DecodeMatch(bodies[0])
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	checkSampleV2(t, match)
+}
+
+func TestSplitMatchesSingle(t *testing.T) {
+	body := readSample(t, "match.json")
+	bodies, err := SplitMatches(body)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(bodies) != 1 || string(bodies[0]) != string(body) {
+		t.Errorf("SplitMatches() de una sola partida debe retornar el body tal cual")
+	}
+}
+
+func TestDecodeMatchV4Sample(t *testing.T) {
+	match, err := DecodeMatch(readSample(t, "region.json"))
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	data := match.Data
+	if data.Metadata.MatchID != sampleID || data.Metadata.Map.Name != "Ascent" {
+		t.Errorf("metadata = %+v", data.Metadata)
+	}
+	// Solo v4 trae el nombre corto de la season
+	if data.Metadata.Season.ID != sampleID || data.Metadata.Season.Short != "e1a1" {
+		t.Errorf("Season = %+v, se esperaba e1a1", data.Metadata.Season)
+	}
+	if len(data.Players) != 1 || data.Players[0].Name != "Henrik3" || data.Players[0].TeamID != "Red" {
+		t.Errorf("jugadores = %+v", data.Players)
+	}
+	if len(data.Rounds) != 1 || len(data.Kills) != 1 || data.Kills[0].Weapon.Name != "Vandal" {
+		t.Errorf("rondas = %d, kills = %+v", len(data.Rounds), data.Kills)
+	}
+}
+
+func TestDecodeMatchInvalid(t *testing.T) {
+	if _, err := DecodeMatch([]byte("no es json")); err == nil {
+		t.Error("DecodeMatch() con un body inválido no retornó error")
+	}
+}
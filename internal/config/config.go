@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 )
@@ -10,32 +11,45 @@ import (
 // Config contiene la configuración de la aplicación cargada desde variables de entorno
 type Config struct {
 	// API Configuration
-	APIKey           string
-	APIRegion        string
-	RequestTimeout   time.Duration
-	MaxRetries       int
+	APIKey         string
+	APIBaseURL     string
+	APIRegion      string
+	RequestTimeout time.Duration
+	MaxRetries     int
+	APIMode        string // live, record o replay
+	FixturesDir    string // Directorio de fixtures para record/replay
 
 	// Rate Limiting
 	MaxRequestsPerMinute int
 	BatchSize            int
 
 	// Player Pool Configuration
-	MainPlayerName     string
-	MainPlayerTag      string
-	PlayerAccountsMap  map[string]string // Mapeo de cuentas de Valorant a nombres reales
-	MinStackPlayers    int
+	MainPlayerName    string
+	MainPlayerTag     string
+	PlayerAccountsMap map[string]string // Riot ID en minúsculas -> nombre real
+	Players           []PlayerProfile   // Roster cargado desde ConfigDir
+	PlayersFile       string            // Archivo del roster (vacío = roster por defecto)
+	MinStackPlayers   int
 
 	// Game Analysis
-	QueueMode            string
-	MaxGamesToAnalyze    int
-	TradeWindowMs        int
-	RecentMatchesToShow  int
-	TimeframeAnalysis    string
+	QueueMode           string
+	MaxGamesToAnalyze   int
+	MatchListSize       int // Partidas completas a pedir en una request a v3/matches en -sync (0 = no usar)
+	TradeWindowMs       int
+	RecentMatchesToShow int
+	TimeframeAnalysis   string
+	RatingWeights       string // Pesos del rating, ej: "kills=0.3,adr=0.2"
+	SynergyMinGames     int    // Partidas mínimas para mostrar una dupla/trío/lineup
 
 	// Storage
 	StatsOutputFile string
 	MatchDataFile   string
+	DatabaseFile    string
+	RawArchiveDir   string
 	ConfigDir       string
+	AccountsFile    string // Cache de PUUIDs e historial de nombres de cada cuenta
+	MapsFile        string // Calibración de coordenadas de cada mapa para los heatmaps
+	HeatmapDir      string
 }
 
 // LoadConfig carga la configuración desde variables de entorno con valores por defecto seguros
@@ -43,9 +57,12 @@ func LoadConfig() (*Config, error) {
 	cfg := &Config{
 		// API Configuration (valores por defecto seguros)
 		APIKey:         getEnv("VALO_API_KEY", ""),
+		APIBaseURL:     getEnv("VALO_API_BASE_URL", "https://api.henrikdev.xyz/valorant"),
 		APIRegion:      getEnv("VALO_REGION", "na"),
 		RequestTimeout: parseDuration(getEnv("VALO_REQUEST_TIMEOUT", "12s"), 12*time.Second),
 		MaxRetries:     parseInt(getEnv("VALO_MAX_RETRIES", "3"), 3),
+		APIMode:        getEnv("VALO_API_MODE", "live"),
+		FixturesDir:    getEnv("VALO_FIXTURES_DIR", "match"),
 
 		// Rate Limiting (30 requests per minute es el límite estricto de la API)
 		MaxRequestsPerMinute: parseInt(getEnv("VALO_MAX_REQUESTS_PER_MINUTE", "30"), 30),
@@ -59,18 +76,27 @@ func LoadConfig() (*Config, error) {
 		// Game Analysis
 		QueueMode:           getEnv("VALO_QUEUE_MODE", "competitive"),
 		MaxGamesToAnalyze:   parseInt(getEnv("VALO_MAX_GAMES", "35"), 35),
+		MatchListSize:       parseInt(getEnv("VALO_MATCHLIST_SIZE", "10"), 10),
 		TradeWindowMs:       parseInt(getEnv("VALO_TRADE_WINDOW_MS", "5000"), 5000),
 		RecentMatchesToShow: parseInt(getEnv("VALO_RECENT_MATCHES_TO_SHOW", "35"), 35),
 		TimeframeAnalysis:   getEnv("VALO_TIMEFRAME", "all"),
+		RatingWeights:       getEnv("VALO_RATING_WEIGHTS", ""),
+		SynergyMinGames:     parseInt(getEnv("VALO_SYNERGY_MIN_GAMES", "3"), 3),
 
 		// Storage
 		StatsOutputFile: getEnv("VALO_STATS_OUTPUT_FILE", "stats.txt"),
 		MatchDataFile:   getEnv("VALO_MATCH_DATA_FILE", "matches.json"),
+		DatabaseFile:    getEnv("VALO_DB_FILE", "data/valo-track.db"),
+		RawArchiveDir:   getEnv("VALO_RAW_DIR", "data/raw"),
 		ConfigDir:       getEnv("VALO_CONFIG_DIR", "./configs"),
+		HeatmapDir:      getEnv("VALO_HEATMAP_DIR", "heatmaps"),
 	}
+	cfg.AccountsFile = getEnv("VALO_ACCOUNTS_FILE", filepath.Join(cfg.ConfigDir, "accounts.json"))
+	cfg.MapsFile = getEnv("VALO_MAPS_FILE", filepath.Join(cfg.ConfigDir, "maps.json"))
 
 	// Validaciones críticas
-	if cfg.APIKey == "" {
+	// En modo replay no se hacen requests reales, así que la API key es opcional
+	if cfg.APIKey == "" && cfg.APIMode != "replay" {
 		return nil, fmt.Errorf("error crítico: VALO_API_KEY no está definida. Define la variable de entorno o usa .env")
 	}
 
@@ -78,47 +104,18 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("error crítico: VALO_MAIN_PLAYER_NAME y VALO_MAIN_PLAYER_TAG deben estar definidas")
 	}
 
-	// Cargar mapeo de cuentas de jugadores
-	cfg.PlayerAccountsMap = getPlayerAccountsMap()
+	// Cargar el roster desde ConfigDir (o el roster por defecto si no hay archivo)
+	players, playersFile, err := LoadPlayers(cfg.ConfigDir)
+	if err != nil {
+		return nil, fmt.Errorf("error cargando jugadores: %w", err)
+	}
+	cfg.Players = players
+	cfg.PlayersFile = playersFile
+	cfg.PlayerAccountsMap = BuildAccountsMap(players)
 
 	return cfg, nil
 }
 
-// getPlayerAccountsMap retorna el mapeo de cuentas de Valorant a nombres reales
-// Este mapeo puede ser cargado desde un archivo JSON en el futuro
-func getPlayerAccountsMap() map[string]string {
-	return map[string]string{
-		// Rosarino
-		"Rosarino#CARC": "Rosarino",
-
-		// Santi
-		"Lessツ#2222":      "Santi",
-		"Cuuurlyta#cutie": "Santi",
-
-		// matutEv
-		"b1chito#LAS":       "matutEv",
-		"Di Maria#CARC":     "matutEv",
-		"matutEv#9439":      "matutEv",
-		"matutEv#CABJ":      "matutEv",
-		"matutEv#VAC":       "matutEv",
-
-		// Dxy
-		"Dxy9#9999":          "Dxy",
-		"matutEv#2003":       "Dxy",
-		"Pokemon Player#Ros": "Dxy",
-
-		// Klowiz
-		"klowiz#cenfe": "Klowiz",
-		"Bnn#6979":     "Klowiz",
-
-		// Pxn
-		"Eliam#qqq": "Pxn",
-
-		// Chapa
-		"Chapa#7851": "Chapa",
-	}
-}
-
 // getEnv obtiene una variable de entorno con un valor por defecto
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PlayersFileNames son los nombres de archivo de roster buscados en ConfigDir, en orden
+var PlayersFileNames = []string{"players.json", "players.yaml", "players.yml"}
+
+// PlayerProfile describe a una persona del stack y las cuentas que usa
+type PlayerProfile struct {
+	Name       string   `json:"name" yaml:"name"`
+	RiotIDs    []string `json:"riot_ids" yaml:"riot_ids"`
+	PUUIDs     []string `json:"puuids,omitempty" yaml:"puuids,omitempty"`
+	Roles      []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+	MainAgents []string `json:"main_agents,omitempty" yaml:"main_agents,omitempty"`
+}
+
+// playersFile es el formato del archivo de roster
+type playersFile struct {
+	Players []PlayerProfile `json:"players" yaml:"players"`
+}
+
+// LoadPlayers lee el roster desde ConfigDir. Retorna la ruta usada, o vacía si
+// no existe ningún archivo (en ese caso se usa el roster por defecto).
+func LoadPlayers(configDir string) ([]PlayerProfile, string, error) {
+	found := make([]string, 0, 1)
+	for _, name := range PlayersFileNames {
+		path := filepath.Join(configDir, name)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return defaultPlayers(), "", nil
+	case 1:
+	default:
+		return nil, "", fmt.Errorf("hay más de un archivo de jugadores en %s (%s): dejar solo uno", configDir, strings.Join(found, ", "))
+	}
+
+	path := found[0]
+	players, err := ReadPlayersFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return players, path, nil
+}
+
+// ReadPlayersFile decodifica y valida un archivo de roster JSON o YAML
+func ReadPlayersFile(path string) ([]PlayerProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo %s: %w", path, err)
+	}
+
+	var file playersFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil {
+			return nil, fmt.Errorf("error decodificando %s: %w", path, err)
+		}
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&file); err != nil {
+			return nil, fmt.Errorf("error decodificando %s: %w", path, err)
+		}
+	}
+
+	if err := ValidatePlayers(file.Players); err != nil {
+		return nil, fmt.Errorf("%s inválido:\n%w", path, err)
+	}
+
+	return file.Players, nil
+}
+
+// ValidatePlayers verifica el roster y retorna todos los problemas encontrados juntos
+func ValidatePlayers(players []PlayerProfile) error {
+	if len(players) == 0 {
+		return fmt.Errorf("  - no hay jugadores definidos")
+	}
+
+	var problems []error
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf("  - "+format, args...))
+	}
+
+	names := make(map[string]int)
+	riotIDs := make(map[string]string)
+	puuids := make(map[string]string)
+
+	for i, player := range players {
+		label := fmt.Sprintf("jugador #%d", i+1)
+		name := strings.TrimSpace(player.Name)
+		if name == "" {
+			addProblem("%s: falta el nombre", label)
+		} else {
+			label = fmt.Sprintf("%s (%s)", label, name)
+			key := strings.ToLower(name)
+			if previous, ok := names[key]; ok {
+				addProblem("%s: nombre duplicado, ya usado por el jugador #%d", label, previous)
+			} else {
+				names[key] = i + 1
+			}
+		}
+
+		if len(player.RiotIDs) == 0 {
+			addProblem("%s: no tiene riot_ids", label)
+		}
+
+		for _, riotID := range player.RiotIDs {
+			if err := ValidateRiotID(riotID); err != nil {
+				addProblem("%s: %v", label, err)
+				continue
+			}
+			key := RiotIDKey(riotID)
+			if owner, ok := riotIDs[key]; ok {
+				addProblem("%s: el Riot ID %q ya está asignado a %s", label, riotID, owner)
+			} else {
+				riotIDs[key] = name
+			}
+		}
+
+		for _, puuid := range player.PUUIDs {
+			puuid = strings.TrimSpace(puuid)
+			if puuid == "" {
+				addProblem("%s: hay un PUUID vacío", label)
+				continue
+			}
+			if owner, ok := puuids[puuid]; ok {
+				addProblem("%s: el PUUID %q ya está asignado a %s", label, puuid, owner)
+			} else {
+				puuids[puuid] = name
+			}
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// ValidateRiotID verifica que el Riot ID tenga el formato nombre#tag
+func ValidateRiotID(riotID string) error {
+	name, tag, ok := strings.Cut(riotID, "#")
+	if !ok || strings.Contains(tag, "#") {
+		return fmt.Errorf("Riot ID %q mal formado: se espera nombre#tag", riotID)
+	}
+	if name != strings.TrimSpace(name) || tag != strings.TrimSpace(tag) {
+		return fmt.Errorf("Riot ID %q mal formado: tiene espacios al inicio o al final", riotID)
+	}
+	if length := utf8.RuneCountInString(name); length < 3 || length > 16 {
+		return fmt.Errorf("Riot ID %q mal formado: el nombre debe tener entre 3 y 16 caracteres", riotID)
+	}
+	if length := utf8.RuneCountInString(tag); length < 3 || length > 5 {
+		return fmt.Errorf("Riot ID %q mal formado: el tag debe tener entre 3 y 5 caracteres", riotID)
+	}
+	return nil
+}
+
+// RiotIDKey normaliza un Riot ID para usarlo como clave: Riot no distingue mayúsculas
+func RiotIDKey(riotID string) string {
+	return strings.ToLower(riotID)
+}
+
+// BuildAccountsMap arma el mapeo de cuentas de Valorant a nombres reales. Las claves
+// son Riot IDs normalizados con RiotIDKey.
+func BuildAccountsMap(players []PlayerProfile) map[string]string {
+	accounts := make(map[string]string)
+	for _, player := range players {
+		for _, riotID := range player.RiotIDs {
+			accounts[RiotIDKey(riotID)] = strings.TrimSpace(player.Name)
+		}
+	}
+	return accounts
+}
+
+// defaultPlayers es el roster original, usado cuando no hay archivo de jugadores
+func defaultPlayers() []PlayerProfile {
+	return []PlayerProfile{
+		{Name: "Rosarino", RiotIDs: []string{"Rosarino#CARC"}},
+		{Name: "Santi", RiotIDs: []string{"Lessツ#2222", "Cuuurlyta#cutie"}},
+		{Name: "matutEv", RiotIDs: []string{"b1chito#LAS", "Di Maria#CARC", "matutEv#9439", "matutEv#CABJ", "matutEv#VAC"}},
+		{Name: "Dxy", RiotIDs: []string{"Dxy9#9999", "matutEv#2003", "Pokemon Player#Ros"}},
+		{Name: "Klowiz", RiotIDs: []string{"klowiz#cenfe", "Bnn#6979"}},
+		{Name: "Pxn", RiotIDs: []string{"Eliam#qqq"}},
+		{Name: "Chapa", RiotIDs: []string{"Chapa#7851"}},
+	}
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateRiotID(t *testing.T) {
+	tests := []struct {
+		riotID  string
+		wantErr string
+	}{
+		{"Rosarino#CARC", ""},
+		{"Lessツ#2222", ""},
+		{"Pokemon Player#Ros", ""},
+		{"abc#12345", ""},
+		{"abcdefghijklmnop#abc", ""},
+		{"Rosarino", "se espera nombre#tag"},
+		{"Ros#ari#no", "se espera nombre#tag"},
+		{" Rosarino#CARC", "espacios"},
+		{"Rosarino#CARC ", "espacios"},
+		{"ab#CARC", "el nombre debe tener"},
+		{"abcdefghijklmnopq#CARC", "el nombre debe tener"},
+		{"Rosarino#CA", "el tag debe tener"},
+		{"Rosarino#CARCAR", "el tag debe tener"},
+		{"#CARC", "el nombre debe tener"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateRiotID(tt.riotID)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateRiotID(%q) = %v, se esperaba nil", tt.riotID, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateRiotID(%q) = %v, se esperaba que contenga %q", tt.riotID, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidatePlayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		players []PlayerProfile
+		want    []string // Fragmentos que deben aparecer en el error (vacío = válido)
+	}{
+		{name: "roster por defecto", players: defaultPlayers()},
+		{name: "sin jugadores", players: nil, want: []string{"no hay jugadores"}},
+		{
+			name:    "sin nombre ni cuentas",
+			players: []PlayerProfile{{Name: "  "}},
+			want:    []string{"jugador #1: falta el nombre", "no tiene riot_ids"},
+		},
+		{
+			name: "nombre duplicado sin distinguir mayúsculas",
+			players: []PlayerProfile{
+				{Name: "Santi", RiotIDs: []string{"Lessツ#2222"}},
+				{Name: "santi", RiotIDs: []string{"Cuuurlyta#cutie"}},
+			},
+			want: []string{"jugador #2 (santi): nombre duplicado, ya usado por el jugador #1"},
+		},
+		{
+			name: "Riot ID duplicado sin distinguir mayúsculas",
+			players: []PlayerProfile{
+				{Name: "Rosarino", RiotIDs: []string{"Rosarino#CARC"}},
+				{Name: "Otro", RiotIDs: []string{"rosarino#carc"}},
+			},
+			want: []string{`el Riot ID "rosarino#carc" ya está asignado a Rosarino`},
+		},
+		{
+			name: "PUUID duplicado o vacío",
+			players: []PlayerProfile{
+				{Name: "Rosarino", RiotIDs: []string{"Rosarino#CARC"}, PUUIDs: []string{"p1"}},
+				{Name: "Otro", RiotIDs: []string{"Otro#1234"}, PUUIDs: []string{" p1 ", ""}},
+			},
+			want: []string{`el PUUID "p1" ya está asignado a Rosarino`, "hay un PUUID vacío"},
+		},
+		{
+			name: "se reportan todos los problemas juntos",
+			players: []PlayerProfile{
+				{Name: "Rosarino", RiotIDs: []string{"Rosarino", "Rosarino#C"}},
+				{Name: "Chapa", RiotIDs: []string{"Chapa#7851"}},
+			},
+			want: []string{"se espera nombre#tag", "el tag debe tener"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePlayers(tt.players)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("error inesperado: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("no hubo error, se esperaba %q", tt.want)
+			}
+			for _, fragment := range tt.want {
+				if !strings.Contains(err.Error(), fragment) {
+					t.Errorf("error = %q, falta %q", err, fragment)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildAccountsMap(t *testing.T) {
+	accounts := BuildAccountsMap([]PlayerProfile{
+		{Name: " Rosarino ", RiotIDs: []string{"Rosarino#CARC"}},
+		{Name: "Santi", RiotIDs: []string{"Lessツ#2222", "Cuuurlyta#cutie"}},
+	})
+
+	want := map[string]string{
+		"rosarino#carc":   "Rosarino",
+		"lessツ#2222":      "Santi",
+		"cuuurlyta#cutie": "Santi",
+	}
+	if len(accounts) != len(want) {
+		t.Errorf("BuildAccountsMap() = %v, se esperaba %v", accounts, want)
+	}
+	for key, player := range want {
+		if accounts[key] != player {
+			t.Errorf("accounts[%q] = %q, se esperaba %q", key, accounts[key], player)
+		}
+	}
+
+	// Cualquier variante de mayúsculas del Riot ID encuentra a la persona
+	for _, riotID := range []string{"Rosarino#CARC", "ROSARINO#carc", "rosarino#Carc"} {
+		if accounts[RiotIDKey(riotID)] != "Rosarino" {
+			t.Errorf("accounts[RiotIDKey(%q)] = %q, se esperaba Rosarino", riotID, accounts[RiotIDKey(riotID)])
+		}
+	}
+}
+
+func TestLoadPlayers(t *testing.T) {
+	write := func(dir, name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("sin archivo usa el roster por defecto", func(t *testing.T) {
+		players, path, err := LoadPlayers(t.TempDir())
+		if err != nil || path != "" || len(players) != len(defaultPlayers()) {
+			t.Errorf("LoadPlayers() = %d jugadores, %q, %v", len(players), path, err)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		dir := t.TempDir()
+		write(dir, "players.json", `{"players": [{"name": "Rosarino", "riot_ids": ["Rosarino#CARC"], "roles": ["igl"]}]}`)
+		players, path, err := LoadPlayers(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != filepath.Join(dir, "players.json") || len(players) != 1 || players[0].Roles[0] != "igl" {
+			t.Errorf("LoadPlayers() = %+v, %q", players, path)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		dir := t.TempDir()
+		write(dir, "players.yaml", "players:\n  - name: Santi\n    riot_ids: [\"Lessツ#2222\", \"Cuuurlyta#cutie\"]\n")
+		players, _, err := LoadPlayers(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(players) != 1 || len(players[0].RiotIDs) != 2 {
+			t.Errorf("LoadPlayers() = %+v", players)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{"campo desconocido en json", map[string]string{"players.json": `{"players": [{"name": "R", "riotids": []}]}`}, "unknown field"},
+		{"campo desconocido en yaml", map[string]string{"players.yml": "players:\n  - name: R\n    riotids: []\n"}, "not found"},
+		{"roster inválido", map[string]string{"players.json": `{"players": [{"name": "R", "riot_ids": ["R"]}]}`}, "players.json inválido"},
+		{"más de un archivo", map[string]string{"players.json": `{}`, "players.yaml": ""}, "más de un archivo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				write(dir, name, content)
+			}
+			_, _, err := LoadPlayers(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+			}
+		})
+	}
+}
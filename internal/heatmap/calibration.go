@@ -0,0 +1,59 @@
+package heatmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Calibration convierte coordenadas del juego de un mapa a coordenadas normalizadas
+// (0 a 1) del minimapa. Usa la misma convención que valorant-api.com: el eje X del
+// minimapa sale de la Y del juego y viceversa.
+type Calibration struct {
+	XMultiplier  float64 `json:"x_multiplier"`
+	YMultiplier  float64 `json:"y_multiplier"`
+	XScalarToAdd float64 `json:"x_scalar_to_add"`
+	YScalarToAdd float64 `json:"y_scalar_to_add"`
+}
+
+// Normalize retorna la posición en el minimapa (0 a 1 en ambos ejes)
+func (c Calibration) Normalize(x, y int) (float64, float64) {
+	u := float64(y)*c.XMultiplier + c.XScalarToAdd
+	v := float64(x)*c.YMultiplier + c.YScalarToAdd
+	return u, v
+}
+
+// Calibrations son las calibraciones por nombre de mapa
+type Calibrations map[string]Calibration
+
+// Lookup busca la calibración de un mapa sin distinguir mayúsculas
+func (cs Calibrations) Lookup(mapName string) (Calibration, bool) {
+	for name, calibration := range cs {
+		if strings.EqualFold(name, mapName) {
+			return calibration, true
+		}
+	}
+	return Calibration{}, false
+}
+
+// LoadCalibrations lee el archivo JSON de calibraciones ({"Ascent": {...}, ...})
+func LoadCalibrations(path string) (Calibrations, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo calibraciones de mapas: %w", err)
+	}
+
+	var calibrations Calibrations
+	if err := json.Unmarshal(data, &calibrations); err != nil {
+		return nil, fmt.Errorf("error en %s: %w", path, err)
+	}
+
+	for name, calibration := range calibrations {
+		if calibration.XMultiplier == 0 || calibration.YMultiplier == 0 {
+			return nil, fmt.Errorf("error en %s: el mapa %s necesita x_multiplier e y_multiplier distintos de 0", path, name)
+		}
+	}
+
+	return calibrations, nil
+}
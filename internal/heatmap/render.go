@@ -0,0 +1,150 @@
+package heatmap
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Point es una kill o una muerte en coordenadas normalizadas del minimapa
+type Point struct {
+	U, V float64
+	Kill bool // true = kill del jugador, false = muerte
+}
+
+// Heatmap son los puntos de un jugador en un mapa (y lado) con su título
+type Heatmap struct {
+	Title  string
+	Points []Point
+}
+
+// Colores de kills y muertes
+var (
+	killColor  = color.NRGBA{R: 46, G: 204, B: 113, A: 255}
+	deathColor = color.NRGBA{R: 231, G: 76, B: 60, A: 255}
+)
+
+// Counts retorna la cantidad de kills y de muertes del heatmap
+func (hm Heatmap) Counts() (int, int) {
+	kills, deaths := 0, 0
+	for _, point := range hm.Points {
+		if point.Kill {
+			kills++
+		} else {
+			deaths++
+		}
+	}
+	return kills, deaths
+}
+
+// WriteSVG dibuja el heatmap como SVG de size×size: cada punto es un círculo difuminado
+// y semitransparente, así que las zonas con muchos eventos se ven más intensas
+func WriteSVG(w io.Writer, hm Heatmap, size int) error {
+	kills, deaths := hm.Counts()
+	radius := float64(size) / 40
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size, size, size, size)
+	fmt.Fprintf(w, `<defs><filter id="blur"><feGaussianBlur stdDeviation="%.1f"/></filter></defs>`+"\n", radius/2)
+	fmt.Fprintf(w, `<rect width="%d" height="%d" fill="#1b1f24"/>`+"\n", size, size)
+	writeSVGGrid(w, size)
+
+	fmt.Fprintf(w, `<g filter="url(#blur)">`+"\n")
+	for _, point := range hm.Points {
+		c := deathColor
+		if point.Kill {
+			c = killColor
+		}
+		fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="#%02x%02x%02x" fill-opacity="0.35"/>`+"\n",
+			point.U*float64(size), point.V*float64(size), radius, c.R, c.G, c.B)
+	}
+	fmt.Fprintf(w, "</g>\n")
+
+	fmt.Fprintf(w, `<text x="10" y="22" fill="#ffffff" font-family="sans-serif" font-size="16">%s</text>`+"\n", html.EscapeString(hm.Title))
+	fmt.Fprintf(w, `<text x="10" y="%d" fill="#%02x%02x%02x" font-family="sans-serif" font-size="13">kills: %d</text>`+"\n",
+		size-28, killColor.R, killColor.G, killColor.B, kills)
+	fmt.Fprintf(w, `<text x="10" y="%d" fill="#%02x%02x%02x" font-family="sans-serif" font-size="13">muertes: %d</text>`+"\n",
+		size-10, deathColor.R, deathColor.G, deathColor.B, deaths)
+	_, err := fmt.Fprintf(w, "</svg>\n")
+	return err
+}
+
+// writeSVGGrid dibuja una grilla de referencia cada 10% del minimapa
+func writeSVGGrid(w io.Writer, size int) {
+	for i := 1; i < 10; i++ {
+		pos := float64(size*i) / 10
+		fmt.Fprintf(w, `<line x1="%.1f" y1="0" x2="%.1f" y2="%d" stroke="#2c323a" stroke-width="1"/>`+"\n", pos, pos, size)
+		fmt.Fprintf(w, `<line x1="0" y1="%.1f" x2="%d" y2="%.1f" stroke="#2c323a" stroke-width="1"/>`+"\n", pos, size, pos)
+	}
+}
+
+// WritePNG dibuja el heatmap como PNG de size×size. Acumula una densidad gaussiana de
+// kills y otra de muertes y pinta cada pixel mezclando ambos colores según su peso.
+func WritePNG(w io.Writer, hm Heatmap, size int) error {
+	killDensity := density(hm.Points, size, true)
+	deathDensity := density(hm.Points, size, false)
+
+	maxDensity := 0.0
+	for i := range killDensity {
+		maxDensity = math.Max(maxDensity, killDensity[i]+deathDensity[i])
+	}
+
+	background := color.NRGBA{R: 27, G: 31, B: 36, A: 255}
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := background
+			if x%(size/10) == 0 || y%(size/10) == 0 {
+				c = color.NRGBA{R: 44, G: 50, B: 58, A: 255} // Grilla cada 10%
+			}
+
+			i := y*size + x
+			total := killDensity[i] + deathDensity[i]
+			if total > 0 && maxDensity > 0 {
+				intensity := math.Min(1, math.Sqrt(total/maxDensity))
+				killShare := killDensity[i] / total
+				c = mix(c, mix(deathColor, killColor, killShare), intensity)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// density acumula un kernel gaussiano por cada punto del tipo pedido
+func density(points []Point, size int, kills bool) []float64 {
+	values := make([]float64, size*size)
+	sigma := float64(size) / 60
+	reach := int(3 * sigma)
+
+	for _, point := range points {
+		if point.Kill != kills {
+			continue
+		}
+		cx, cy := point.U*float64(size), point.V*float64(size)
+		for y := int(cy) - reach; y <= int(cy)+reach; y++ {
+			for x := int(cx) - reach; x <= int(cx)+reach; x++ {
+				if x < 0 || y < 0 || x >= size || y >= size {
+					continue
+				}
+				dx, dy := float64(x)-cx, float64(y)-cy
+				values[y*size+x] += math.Exp(-(dx*dx + dy*dy) / (2 * sigma * sigma))
+			}
+		}
+	}
+	return values
+}
+
+// mix interpola entre dos colores (t = 0 → a, t = 1 → b)
+func mix(a, b color.NRGBA, t float64) color.NRGBA {
+	return color.NRGBA{
+		R: uint8(float64(a.R)*(1-t) + float64(b.R)*t),
+		G: uint8(float64(a.G)*(1-t) + float64(b.G)*t),
+		B: uint8(float64(a.B)*(1-t) + float64(b.B)*t),
+		A: 255,
+	}
+}
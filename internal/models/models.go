@@ -22,16 +22,37 @@ type PlayerStats struct {
 	KASTRounds     int // Rondas con Kill, Assist, Survive o Trade
 
 	// Stats por lado
-	AttackKills   int
-	AttackDeaths  int
-	AttackDamage  int
-	AttackRounds  int
-	DefenseKills  int
-	DefenseDeaths int
-	DefenseDamage int
-	DefenseRounds int
-	MultiKills    map[int]int // 2K/3K/4K/5K (ace)
-	Clutches      int
+	AttackKills    int
+	AttackDeaths   int
+	AttackDamage   int
+	AttackRounds   int
+	DefenseKills   int
+	DefenseDeaths  int
+	DefenseDamage  int
+	DefenseRounds  int
+	MultiKills     map[int]int // 2K/3K/4K/5K (ace)
+	Clutches       int         // Clutches ganados
+	ClutchAttempts map[int]int // Situaciones 1vN por cantidad de rivales
+	ClutchWins     map[int]int
+	Rating         float64 // Rating compuesto (1.00 = promedio)
+
+	// Economía (solo rondas con datos de economía)
+	LoadoutValue  int // Suma del valor de equipamiento por ronda
+	CreditsSpent  int
+	EconomyDamage int // Daño hecho en esas rondas
+
+	Weapons map[string]WeaponStats // Por nombre de arma
+}
+
+// WeaponStats contiene los stats de un jugador con un arma. Las kills salen del arma de
+// cada kill; rondas, daño y disparos salen de las rondas en las que era su arma comprada.
+type WeaponStats struct {
+	Kills     int
+	Rounds    int
+	Damage    int
+	Headshots int
+	Bodyshots int
+	Legshots  int
 }
 
 // MatchData contiene los datos de una partida y su análisis
@@ -48,17 +69,54 @@ type MatchData struct {
 	PlayerTeams  map[string]string // TeamID (Red o Blue)
 
 	// Stats por lado
-	AttackKills   map[string]int
-	AttackDeaths  map[string]int
-	AttackDamage  map[string]int
-	AttackRounds  map[string]int
-	DefenseKills  map[string]int
-	DefenseDeaths map[string]int
-	DefenseDamage map[string]int
-	DefenseRounds map[string]int
-	MultiKills    map[string]map[int]int
-	Clutches      map[string]int
-	Timestamp     int64 // Timestamp de la partida
+	AttackKills    map[string]int
+	AttackDeaths   map[string]int
+	AttackDamage   map[string]int
+	AttackRounds   map[string]int
+	DefenseKills   map[string]int
+	DefenseDeaths  map[string]int
+	DefenseDamage  map[string]int
+	DefenseRounds  map[string]int
+	MultiKills     map[string]map[int]int
+	Clutches       map[string]int
+	ClutchAttempts map[string]map[int]int // Situaciones 1vN por jugador y cantidad de rivales
+	ClutchWins     map[string]map[int]int
+	LoadoutValue   map[string]int // Suma del valor de equipamiento por ronda
+	CreditsSpent   map[string]int
+	EconomyDamage  map[string]int                    // Daño hecho en rondas con datos de economía
+	Weapons        map[string]map[string]WeaponStats // Jugador -> arma -> stats
+	Timestamp      int64                             // Timestamp de la partida
+	Season         string
+	SeasonID       string // UUID de la season (las partidas v2 traen solo este)
+
+	// Detalle por ronda para consultas y análisis posteriores
+	Rounds []RoundData
+	Kills  []KillEvent
+}
+
+// RoundData resume el resultado de una ronda
+type RoundData struct {
+	Round         int
+	WinningTeam   string
+	AttackingTeam string
+	Result        string                 // Eliminated, Detonate, Defuse, ...
+	Economy       map[string]TeamEconomy // Economía de cada equipo (por TeamID)
+
+	// Spike (el plant siempre es del equipo atacante)
+	PlantSite   string // A, B o C; vacío si no se plantó
+	PlantTimeMs int    // Momento del plant dentro de la ronda
+	Planter     string // Nombre real si plantó alguien del stack
+	Defused     bool
+	Defuser     string // Nombre real si defuseó alguien del stack
+}
+
+// TeamEconomy resume la economía de un equipo en una ronda
+type TeamEconomy struct {
+	Loadout   int // Valor total del equipamiento del equipo
+	Spent     int
+	Remaining int
+	Players   int
+	Buy       string // pistol, eco, force, half o full
 }
 
 // PlayerMatchStats contiene los stats de un jugador en una partida específica
@@ -87,26 +145,21 @@ type KillEvent struct {
 	KillerPUUID string
 	VictimPUUID string
 	Assistants  []string
-}
+	Weapon      string // Nombre del arma (o "Ability" si fue con una habilidad)
 
-// AnalysisRequest representa una solicitud de análisis para un usuario
-type AnalysisRequest struct {
-	PlayerName string
-	PlayerTag  string
-	Region     string
-	QueueMode  string
-	MaxGames   int
-	Priority   int // Para ordenamiento en cola
+	// Posiciones en coordenadas del juego (nil si la API no las trae)
+	KillerLocation *Location
+	VictimLocation *Location
 }
 
-// AnalysisResult contiene el resultado del análisis de un usuario
-type AnalysisResult struct {
-	PlayerName string
-	PlayerTag  string
-	Stats      *PlayerStats
-	Matches    []MatchData
-	Error      error
-	Timestamp  int64
+// SyncReport resume el resultado de una sincronización incremental de partidas
+type SyncReport struct {
+	New       int // Partidas descargadas y agregadas al histórico
+	Skipped   int // Partidas que ya estaban guardadas
+	Ignored   int // Partidas descartadas por el análisis en una sincronización anterior
+	Failed    int // Partidas que no se pudieron descargar
+	Discarded int // Partidas sin suficientes jugadores del stack
+	Total     int // Partidas en el histórico tras la sincronización
 }
 
 // RateLimitStatus contiene información sobre el estado del rate limiter
@@ -116,3 +169,270 @@ type RateLimitStatus struct {
 	ResetTime         int64
 	IsThrottled       bool
 }
+
+// Structs para respuestas de API v4
+
+type V4MatchPlayer struct {
+	PUUID  string `json:"puuid"`
+	Name   string `json:"name"`
+	Tag    string `json:"tag"`
+	TeamID string `json:"team_id"`
+	Agent  struct {
+		Name string `json:"name"`
+	} `json:"agent"`
+	Stats struct {
+		Score     int `json:"score"`
+		Kills     int `json:"kills"`
+		Deaths    int `json:"deaths"`
+		Assists   int `json:"assists"`
+		Headshots int `json:"headshots"`
+		Bodyshots int `json:"bodyshots"`
+		Legshots  int `json:"legshots"`
+		Damage    struct {
+			Dealt    int `json:"dealt"`
+			Received int `json:"received"`
+		} `json:"damage"`
+	} `json:"stats"`
+}
+
+// V4PlayerRef identifica a un jugador dentro de un evento (kill, plant, stats de ronda)
+type V4PlayerRef struct {
+	PUUID string `json:"puuid"`
+	Name  string `json:"name"`
+	Tag   string `json:"tag"`
+	Team  string `json:"team"`
+}
+
+// V4Item es un arma o un escudo
+type V4Item struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// V4Economy es la economía de un jugador en una ronda
+type V4Economy struct {
+	LoadoutValue int     `json:"loadout_value"`
+	Spent        int     `json:"spent"`
+	Remaining    int     `json:"remaining"`
+	Weapon       *V4Item `json:"weapon"`
+	Armor        *V4Item `json:"armor"`
+}
+
+type V4RoundStatsEntry struct {
+	Player V4PlayerRef `json:"player"`
+	Stats  struct {
+		Damage    int `json:"damage"`
+		Kills     int `json:"kills"`
+		Assists   int `json:"assists"`
+		Score     int `json:"score"`
+		Headshots int `json:"headshots"`
+		Bodyshots int `json:"bodyshots"`
+		Legshots  int `json:"legshots"`
+	} `json:"stats"`
+	Economy *V4Economy `json:"economy"`
+}
+
+// V4Plant es el plant de la spike en una ronda
+type V4Plant struct {
+	RoundTimeInMs int         `json:"round_time_in_ms"`
+	Site          string      `json:"site"`
+	Player        V4PlayerRef `json:"player"`
+}
+
+// V4Defuse es el defuse de la spike en una ronda
+type V4Defuse struct {
+	RoundTimeInMs int         `json:"round_time_in_ms"`
+	Player        V4PlayerRef `json:"player"`
+}
+
+type V4Round struct {
+	ID          int                 `json:"id"`
+	Result      string              `json:"result"`
+	WinningTeam string              `json:"winning_team"`
+	Plant       *V4Plant            `json:"plant"`
+	Defuse      *V4Defuse           `json:"defuse"`
+	Stats       []V4RoundStatsEntry `json:"stats"`
+}
+
+// V4PlayerLocation es la posición de un jugador vivo al momento de una kill
+type V4PlayerLocation struct {
+	Player      V4PlayerRef `json:"player"`
+	ViewRadians float64     `json:"view_radians"`
+	Location    Location    `json:"location"`
+}
+
+type V4KillEventResponse struct {
+	Round           int                `json:"round"`
+	TimeInRoundInMs int                `json:"time_in_round_in_ms"`
+	Killer          V4PlayerRef        `json:"killer"`
+	Victim          V4PlayerRef        `json:"victim"`
+	Assistants      []V4PlayerRef      `json:"assistants"`
+	Weapon          V4Item             `json:"weapon"`           // Name vacío en kills con habilidades; Type: Weapon, Ability, ...
+	Location        *Location          `json:"location"`         // Donde murió la víctima
+	PlayerLocations []V4PlayerLocation `json:"player_locations"` // Posición de cada jugador vivo al momento de la kill
+}
+
+// Location es una posición en coordenadas del juego
+type Location struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type V4MatchResponse struct {
+	Status int `json:"status"`
+	Data   struct {
+		ID       string `json:"id"`
+		Metadata struct {
+			MatchID string `json:"match_id"`
+			Map     struct {
+				Name string `json:"name"`
+			} `json:"map"`
+			Queue struct {
+				ID string `json:"id"`
+			} `json:"queue"`
+			Region    string `json:"region"`
+			GameStart int64  `json:"game_start"`
+			StartedAt string `json:"started_at"`
+			Season    struct {
+				ID    string `json:"id"`
+				Short string `json:"short"`
+			} `json:"season"`
+		} `json:"metadata"`
+		Players []V4MatchPlayer       `json:"players"`
+		Teams   []V4Team              `json:"teams"`
+		Rounds  []V4Round             `json:"rounds"`
+		Kills   []V4KillEventResponse `json:"kills"`
+	} `json:"data"`
+}
+
+// V4Team es el resultado de un equipo en la partida
+type V4Team struct {
+	TeamID string `json:"team_id"`
+	Rounds struct {
+		Won  int `json:"won"`
+		Lost int `json:"lost"`
+	} `json:"rounds"`
+	Won bool `json:"won"`
+}
+
+// Structs para respuestas de API v2 (v2/match y la lista v3/matches). Solo se decodifican
+// los campos que usa el análisis; api.ConvertMatchV2 los lleva al formato v4.
+
+// V2MatchResponse es la respuesta de v2/match/{id}
+type V2MatchResponse struct {
+	Status int     `json:"status"`
+	Data   V2Match `json:"data"`
+}
+
+type V2Match struct {
+	Metadata struct {
+		MatchID      string `json:"matchid"`
+		Map          string `json:"map"`
+		GameStart    int64  `json:"game_start"`
+		RoundsPlayed int    `json:"rounds_played"`
+		Mode         string `json:"mode"`
+		ModeID       string `json:"mode_id"`
+		Queue        string `json:"queue"`
+		SeasonID     string `json:"season_id"`
+		Region       string `json:"region"`
+	} `json:"metadata"`
+	Players struct {
+		AllPlayers []V2Player `json:"all_players"`
+	} `json:"players"`
+	Teams  map[string]V2Team `json:"teams"` // "red" y "blue"
+	Rounds []V2Round         `json:"rounds"`
+}
+
+type V2Player struct {
+	PUUID     string `json:"puuid"`
+	Name      string `json:"name"`
+	Tag       string `json:"tag"`
+	Team      string `json:"team"`
+	Character string `json:"character"`
+	Stats     struct {
+		Score     int `json:"score"`
+		Kills     int `json:"kills"`
+		Deaths    int `json:"deaths"`
+		Assists   int `json:"assists"`
+		Bodyshots int `json:"bodyshots"`
+		Headshots int `json:"headshots"`
+		Legshots  int `json:"legshots"`
+	} `json:"stats"`
+	DamageMade     int `json:"damage_made"`
+	DamageReceived int `json:"damage_received"`
+}
+
+type V2Team struct {
+	HasWon     *bool `json:"has_won"` // nil en modos sin equipos
+	RoundsWon  int   `json:"rounds_won"`
+	RoundsLost int   `json:"rounds_lost"`
+}
+
+// V2EventPlayer identifica a quien planta o defusea la spike
+type V2EventPlayer struct {
+	PUUID       string `json:"puuid"`
+	DisplayName string `json:"display_name"` // Nombre#Tag
+	Team        string `json:"team"`
+}
+
+type V2Round struct {
+	WinningTeam string `json:"winning_team"`
+	EndType     string `json:"end_type"`
+	BombPlanted bool   `json:"bomb_planted"`
+	BombDefused bool   `json:"bomb_defused"`
+	PlantEvents struct {
+		PlantedBy        *V2EventPlayer `json:"planted_by"`
+		PlantSite        string         `json:"plant_site"`
+		PlantTimeInRound int            `json:"plant_time_in_round"`
+	} `json:"plant_events"`
+	DefuseEvents struct {
+		DefusedBy         *V2EventPlayer `json:"defused_by"`
+		DefuseTimeInRound int            `json:"defuse_time_in_round"`
+	} `json:"defuse_events"`
+	PlayerStats []V2RoundPlayerStats `json:"player_stats"`
+}
+
+type V2RoundPlayerStats struct {
+	PlayerPUUID       string        `json:"player_puuid"`
+	PlayerDisplayName string        `json:"player_display_name"`
+	PlayerTeam        string        `json:"player_team"`
+	Damage            int           `json:"damage"`
+	Bodyshots         int           `json:"bodyshots"`
+	Headshots         int           `json:"headshots"`
+	Legshots          int           `json:"legshots"`
+	Kills             int           `json:"kills"`
+	Score             int           `json:"score"`
+	KillEvents        []V2KillEvent `json:"kill_events"`
+	Economy           *struct {
+		LoadoutValue int     `json:"loadout_value"`
+		Remaining    int     `json:"remaining"`
+		Spent        int     `json:"spent"`
+		Weapon       *V4Item `json:"weapon"`
+		Armor        *V4Item `json:"armor"`
+	} `json:"economy"`
+}
+
+type V2KillEvent struct {
+	KillTimeInRound   int       `json:"kill_time_in_round"`
+	KillerPUUID       string    `json:"killer_puuid"`
+	KillerDisplayName string    `json:"killer_display_name"`
+	KillerTeam        string    `json:"killer_team"`
+	VictimPUUID       string    `json:"victim_puuid"`
+	VictimDisplayName string    `json:"victim_display_name"`
+	VictimTeam        string    `json:"victim_team"`
+	VictimLocation    *Location `json:"victim_death_location"`
+	WeaponID          string    `json:"damage_weapon_id"`
+	WeaponName        string    `json:"damage_weapon_name"`
+	PlayerLocations   []struct {
+		PlayerPUUID string   `json:"player_puuid"`
+		PlayerTeam  string   `json:"player_team"`
+		ViewRadians float64  `json:"view_radians"`
+		Location    Location `json:"location"`
+	} `json:"player_locations_on_kill"`
+	Assistants []struct {
+		PUUID       string `json:"assistant_puuid"`
+		DisplayName string `json:"assistant_display_name"`
+		Team        string `json:"assistant_team"`
+	} `json:"assistants"`
+}
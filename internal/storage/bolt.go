@@ -0,0 +1,357 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"valo-track/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets de la base de datos
+var (
+	bucketMatches = []byte("matches") // matchID -> MatchData
+	bucketByTime  = []byte("by_time") // timestamp + matchID -> matchID
+	bucketPlayers = []byte("players") // matchID/jugador -> PlayerMatchRow
+	bucketRounds  = []byte("rounds")  // matchID/índice -> RoundRow
+	bucketKills   = []byte("kills")   // matchID/índice -> KillRow
+
+	bucketDiscarded = []byte("discarded") // matchID -> vacío, partidas descartadas por el análisis
+)
+
+// BoltStore implementa Store sobre una base embebida en un único archivo
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// Open abre (o crea) la base de datos en la ruta indicada
+func Open(path string) (*BoltStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("error creando directorio de la base: %w", err)
+		}
+	}
+
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo base de datos %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketMatches, bucketByTime, bucketPlayers, bucketRounds, bucketKills, bucketDiscarded} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error inicializando base de datos: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Close cierra la base de datos
+func (bs *BoltStore) Close() error {
+	return bs.db.Close()
+}
+
+// SaveMatch guarda una partida reemplazando la versión anterior si existía
+func (bs *BoltStore) SaveMatch(match models.MatchData) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return putMatch(tx, &match)
+	})
+}
+
+// SaveMatches guarda varias partidas en una sola transacción
+func (bs *BoltStore) SaveMatches(matches []models.MatchData) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		for i := range matches {
+			if err := putMatch(tx, &matches[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// HasMatch indica si la partida ya está guardada
+func (bs *BoltStore) HasMatch(matchID string) (bool, error) {
+	found := false
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(bucketMatches).Get([]byte(matchID)) != nil
+		return nil
+	})
+	return found, err
+}
+
+// Count retorna la cantidad de partidas guardadas
+func (bs *BoltStore) Count() (int, error) {
+	count := 0
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(bucketMatches).Stats().KeyN
+		return nil
+	})
+	return count, err
+}
+
+// MarkDiscarded registra una partida descartada por el análisis
+func (bs *BoltStore) MarkDiscarded(matchID string) error {
+	if matchID == "" {
+		return fmt.Errorf("partida sin MatchID")
+	}
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketDiscarded).Put([]byte(matchID), []byte{})
+	})
+}
+
+// IsDiscarded indica si la partida se descartó antes y no se guardó desde entonces
+func (bs *BoltStore) IsDiscarded(matchID string) (bool, error) {
+	found := false
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(bucketDiscarded).Get([]byte(matchID)) != nil
+		return nil
+	})
+	return found, err
+}
+
+// Matches retorna las partidas que cumplen el filtro, ordenadas por fecha
+func (bs *BoltStore) Matches(filter Filter) ([]models.MatchData, error) {
+	matches := make([]models.MatchData, 0)
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return forEachMatch(tx, filter, func(match *models.MatchData) error {
+			matches = append(matches, *match)
+			return nil
+		})
+	})
+	return matches, err
+}
+
+// PlayerMatches retorna una fila por jugador del stack y partida
+func (bs *BoltStore) PlayerMatches(filter Filter) ([]PlayerMatchRow, error) {
+	rows := make([]PlayerMatchRow, 0)
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return forEachMatch(tx, filter, func(match *models.MatchData) error {
+			return scanPrefix(tx.Bucket(bucketPlayers), match.MatchID, func(value []byte) error {
+				var row PlayerMatchRow
+				if err := json.Unmarshal(value, &row); err != nil {
+					return err
+				}
+				if filter.matchesPlayerRow(&row) {
+					rows = append(rows, row)
+				}
+				return nil
+			})
+		})
+	})
+	return rows, err
+}
+
+// Rounds retorna las rondas de las partidas que cumplen el filtro
+func (bs *BoltStore) Rounds(filter Filter) ([]RoundRow, error) {
+	rows := make([]RoundRow, 0)
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return forEachMatch(tx, filter, func(match *models.MatchData) error {
+			return scanPrefix(tx.Bucket(bucketRounds), match.MatchID, func(value []byte) error {
+				var row RoundRow
+				if err := json.Unmarshal(value, &row); err != nil {
+					return err
+				}
+				rows = append(rows, row)
+				return nil
+			})
+		})
+	})
+	return rows, err
+}
+
+// Kills retorna los eventos de muerte de las partidas que cumplen el filtro.
+// Si el filtro tiene jugador, solo se incluyen kills y muertes de ese jugador.
+func (bs *BoltStore) Kills(filter Filter) ([]KillRow, error) {
+	rows := make([]KillRow, 0)
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return forEachMatch(tx, filter, func(match *models.MatchData) error {
+			return scanPrefix(tx.Bucket(bucketKills), match.MatchID, func(value []byte) error {
+				var row KillRow
+				if err := json.Unmarshal(value, &row); err != nil {
+					return err
+				}
+				if filter.matchesKill(&row.KillEvent) {
+					rows = append(rows, row)
+				}
+				return nil
+			})
+		})
+	})
+	return rows, err
+}
+
+// putMatch escribe una partida y sus filas derivadas dentro de una transacción
+func putMatch(tx *bolt.Tx, match *models.MatchData) error {
+	if match.MatchID == "" {
+		return fmt.Errorf("partida sin MatchID")
+	}
+
+	if err := deleteMatch(tx, match.MatchID); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketDiscarded).Delete([]byte(match.MatchID)); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(match)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketMatches).Put([]byte(match.MatchID), data); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketByTime).Put(timeKey(match.Timestamp, match.MatchID), []byte(match.MatchID)); err != nil {
+		return err
+	}
+
+	for _, row := range BuildPlayerRows(match) {
+		if err := putJSON(tx.Bucket(bucketPlayers), rowKey(match.MatchID, []byte(row.Player)), row); err != nil {
+			return err
+		}
+	}
+
+	for i, round := range match.Rounds {
+		row := RoundRow{MatchID: match.MatchID, Timestamp: match.Timestamp, Map: match.Map, RoundData: round}
+		if err := putJSON(tx.Bucket(bucketRounds), rowKey(match.MatchID, indexKey(i)), row); err != nil {
+			return err
+		}
+	}
+
+	for i, kill := range match.Kills {
+		row := KillRow{MatchID: match.MatchID, Timestamp: match.Timestamp, Map: match.Map, Index: i, KillEvent: kill}
+		if err := putJSON(tx.Bucket(bucketKills), rowKey(match.MatchID, indexKey(i)), row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteMatch elimina una partida y todas sus filas derivadas
+func deleteMatch(tx *bolt.Tx, matchID string) error {
+	previous := tx.Bucket(bucketMatches).Get([]byte(matchID))
+	if previous == nil {
+		return nil
+	}
+
+	var old models.MatchData
+	if err := json.Unmarshal(previous, &old); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketByTime).Delete(timeKey(old.Timestamp, matchID)); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketMatches).Delete([]byte(matchID)); err != nil {
+		return err
+	}
+
+	for _, name := range [][]byte{bucketPlayers, bucketRounds, bucketKills} {
+		bucket := tx.Bucket(name)
+		prefix := rowKey(matchID, nil)
+		cursor := bucket.Cursor()
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Seek(prefix) {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// forEachMatch recorre en orden cronológico las partidas que cumplen el filtro
+func forEachMatch(tx *bolt.Tx, filter Filter, fn func(match *models.MatchData) error) error {
+	matches := tx.Bucket(bucketMatches)
+	cursor := tx.Bucket(bucketByTime).Cursor()
+
+	var k, v []byte
+	if filter.From.IsZero() {
+		k, v = cursor.First()
+	} else {
+		k, v = cursor.Seek(timeKey(filter.From.Unix(), ""))
+	}
+
+	for ; k != nil; k, v = cursor.Next() {
+		timestamp := int64(binary.BigEndian.Uint64(k[:8]))
+		if !filter.To.IsZero() && timestamp >= filter.To.Unix() {
+			break
+		}
+
+		data := matches.Get(v)
+		if data == nil {
+			continue
+		}
+
+		var match models.MatchData
+		if err := json.Unmarshal(data, &match); err != nil {
+			return fmt.Errorf("error decodificando partida %s: %w", v, err)
+		}
+		if !filter.matchesMatch(&match) {
+			continue
+		}
+		if err := fn(&match); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanPrefix recorre las filas de un bucket que pertenecen a una partida
+func scanPrefix(bucket *bolt.Bucket, matchID string, fn func(value []byte) error) error {
+	prefix := rowKey(matchID, nil)
+	cursor := bucket.Cursor()
+	for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putJSON serializa un valor y lo guarda en el bucket
+func putJSON(bucket *bolt.Bucket, key []byte, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+// timeKey construye una clave ordenable por fecha (timestamps negativos se tratan como 0)
+func timeKey(timestamp int64, matchID string) []byte {
+	if timestamp < 0 {
+		timestamp = 0
+	}
+	key := make([]byte, 8, 8+len(matchID))
+	binary.BigEndian.PutUint64(key, uint64(timestamp))
+	return append(key, matchID...)
+}
+
+// rowKey construye la clave matchID/sufijo de una fila derivada
+func rowKey(matchID string, suffix []byte) []byte {
+	key := make([]byte, 0, len(matchID)+1+len(suffix))
+	key = append(key, matchID...)
+	key = append(key, '/')
+	return append(key, suffix...)
+}
+
+// indexKey codifica un índice para que las filas queden ordenadas
+func indexKey(i int) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key, uint32(i))
+	return key
+}
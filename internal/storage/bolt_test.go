@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"valo-track/internal/models"
+)
+
+// openTestStore abre una base vacía en un directorio temporal
+func openTestStore(t *testing.T) *BoltStore {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "data", "test.db"))
+	if err != nil {
+		t.Fatalf("error abriendo base: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// testMatch arma una partida con una ronda y una kill por cada índice de rounds
+func testMatch(id string, timestamp int64, mapName, mode string, players map[string]string, rounds int) models.MatchData {
+	match := models.MatchData{
+		MatchID:      id,
+		Map:          mapName,
+		Mode:         mode,
+		Timestamp:    timestamp,
+		RoundsPlayed: rounds,
+		PlayerData:   make(map[string]models.PlayerMatchStats),
+	}
+	for player, agent := range players {
+		match.PlayerData[player] = models.PlayerMatchStats{Agent: agent, Kills: rounds}
+	}
+	for i := 0; i < rounds; i++ {
+		match.Rounds = append(match.Rounds, models.RoundData{Round: i, WinningTeam: "Red"})
+		match.Kills = append(match.Kills, models.KillEvent{Round: i, KillerName: "Santi", VictimName: "Rival"})
+	}
+	return match
+}
+
+func matchIDs(matches []models.MatchData) string {
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.MatchID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSaveMatchReplaces(t *testing.T) {
+	store := openTestStore(t)
+
+	// m10 comparte el prefijo "m1" pero no el de sus filas ("m1/")
+	if err := store.SaveMatches([]models.MatchData{
+		testMatch("m1", 100, "Ascent", "competitive", map[string]string{"Santi": "Jett", "Rosarino": "Sova"}, 3),
+		testMatch("m10", 200, "Bind", "competitive", map[string]string{"Santi": "Raze"}, 2),
+	}); err != nil {
+		t.Fatalf("error guardando: %v", err)
+	}
+
+	// Reemplazar m1 con otra fecha, menos rondas y un jugador menos
+	if err := store.SaveMatch(testMatch("m1", 300, "Ascent", "competitive", map[string]string{"Santi": "Jett"}, 1)); err != nil {
+		t.Fatalf("error reemplazando: %v", err)
+	}
+
+	if count, _ := store.Count(); count != 2 {
+		t.Errorf("Count() = %d, se esperaba 2", count)
+	}
+
+	matches, err := store.Matches(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// La clave vieja de by_time se borró: m1 aparece una sola vez y con la fecha nueva
+	if got := matchIDs(matches); got != "m10,m1" {
+		t.Errorf("Matches() = %s, se esperaba m10,m1", got)
+	}
+
+	rounds, err := store.Rounds(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	perMatch := make(map[string]int)
+	for _, row := range rounds {
+		perMatch[row.MatchID]++
+	}
+	if perMatch["m1"] != 1 || perMatch["m10"] != 2 {
+		t.Errorf("rondas por partida = %v, se esperaba m1:1 m10:2", perMatch)
+	}
+
+	kills, err := store.Kills(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	perMatch = make(map[string]int)
+	for _, row := range kills {
+		perMatch[row.MatchID]++
+		if row.MatchID == "m1" && row.Timestamp != 300 {
+			t.Errorf("kill de m1 con timestamp %d, se esperaba 300", row.Timestamp)
+		}
+	}
+	if perMatch["m1"] != 1 || perMatch["m10"] != 2 {
+		t.Errorf("kills por partida = %v, se esperaba m1:1 m10:2", perMatch)
+	}
+
+	rows, err := store.PlayerMatches(Filter{Player: "Rosarino"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("filas de Rosarino = %d, se esperaba 0 tras el reemplazo", len(rows))
+	}
+}
+
+func TestMatchesOrderedByTime(t *testing.T) {
+	store := openTestStore(t)
+
+	for _, match := range []models.MatchData{
+		testMatch("c", 3000, "Ascent", "competitive", nil, 0),
+		testMatch("a", 1000, "Ascent", "competitive", nil, 0),
+		testMatch("b2", 2000, "Ascent", "competitive", nil, 0),
+		testMatch("b1", 2000, "Ascent", "competitive", nil, 0), // Mismo timestamp: desempata el MatchID
+		testMatch("neg", -5, "Ascent", "competitive", nil, 0),  // Negativo se indexa como 0
+	} {
+		if err := store.SaveMatch(match); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matches, err := store.Matches(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := matchIDs(matches); got != "neg,a,b1,b2,c" {
+		t.Errorf("Matches() = %s, se esperaba neg,a,b1,b2,c", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	store := openTestStore(t)
+
+	day := func(d int) int64 {
+		return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC).Unix()
+	}
+	if err := store.SaveMatches([]models.MatchData{
+		testMatch("m1", day(1), "Ascent", "competitive", map[string]string{"Santi": "Jett", "Rosarino": "Sova"}, 2),
+		testMatch("m2", day(2), "Bind", "competitive", map[string]string{"Santi": "Raze"}, 2),
+		testMatch("m3", day(3), "Ascent", "unrated", map[string]string{"Rosarino": "Jett"}, 2),
+		testMatch("m4", day(4), "Haven", "competitive", map[string]string{"Rosarino": "Omen"}, 2),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{"sin filtro", Filter{}, "m1,m2,m3,m4"},
+		{"from inclusivo", Filter{From: time.Unix(day(2), 0)}, "m2,m3,m4"},
+		{"to exclusivo", Filter{To: time.Unix(day(3), 0)}, "m1,m2"},
+		{"rango", Filter{From: time.Unix(day(2), 0), To: time.Unix(day(4), 0)}, "m2,m3"},
+		{"mapa sin distinguir mayúsculas", Filter{Map: "ascent"}, "m1,m3"},
+		{"queue", Filter{Queue: "Unrated"}, "m3"},
+		{"agente de cualquier jugador", Filter{Agent: "jett"}, "m1,m3"},
+		{"jugador", Filter{Player: "Santi"}, "m1,m2"},
+		{"jugador y agente", Filter{Player: "Rosarino", Agent: "Jett"}, "m3"},
+		{"todo combinado", Filter{From: time.Unix(day(2), 0), Map: "Haven", Queue: "competitive", Player: "Rosarino", Agent: "Omen"}, "m4"},
+		{"sin resultados", Filter{Map: "Lotus"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := store.Matches(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := matchIDs(matches); got != tt.want {
+				t.Errorf("Matches() = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+
+	// Las filas por jugador aplican jugador y agente sobre cada fila, no sobre la partida
+	rows, err := store.PlayerMatches(Filter{Agent: "Jett"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, row := range rows {
+		got = append(got, row.MatchID+"/"+row.Player)
+	}
+	if strings.Join(got, ",") != "m1/Santi,m3/Rosarino" {
+		t.Errorf("PlayerMatches(Jett) = %v, se esperaba [m1/Santi m3/Rosarino]", got)
+	}
+
+	// Las kills se filtran por jugador involucrado
+	kills, err := store.Kills(Filter{Player: "Rosarino"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kills) != 0 {
+		t.Errorf("kills de Rosarino = %d, se esperaba 0", len(kills))
+	}
+	if kills, _ := store.Kills(Filter{Player: "Santi", Map: "Bind"}); len(kills) != 2 {
+		t.Errorf("kills de Santi en Bind = %d, se esperaba 2", len(kills))
+	}
+}
+
+func TestDiscarded(t *testing.T) {
+	store := openTestStore(t)
+
+	if err := store.MarkDiscarded("m1"); err != nil {
+		t.Fatal(err)
+	}
+	if discarded, _ := store.IsDiscarded("m1"); !discarded {
+		t.Error("IsDiscarded(m1) = false tras MarkDiscarded")
+	}
+	if discarded, _ := store.IsDiscarded("m2"); discarded {
+		t.Error("IsDiscarded(m2) = true sin marcar")
+	}
+
+	// Guardarla después (por ejemplo, con otro roster) la saca del registro
+	if err := store.SaveMatch(testMatch("m1", 100, "Ascent", "competitive", nil, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if discarded, _ := store.IsDiscarded("m1"); discarded {
+		t.Error("IsDiscarded(m1) = true tras guardarla")
+	}
+
+	if err := store.MarkDiscarded(""); err == nil {
+		t.Error("MarkDiscarded(\"\") no retornó error")
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	store := openTestStore(t)
+
+	if err := store.SaveMatch(testMatch("m1", 100, "Ascent", "competitive", nil, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal([]models.MatchData{
+		testMatch("m1", 999, "Bind", "competitive", nil, 0), // Ya existe: no se reemplaza
+		testMatch("m2", 200, "Bind", "competitive", map[string]string{"Santi": "Raze"}, 2),
+		testMatch("", 300, "Bind", "competitive", nil, 0), // Sin MatchID
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "matches.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, skipped, err := ImportJSON(store, path)
+	if err != nil {
+		t.Fatalf("error importando: %v", err)
+	}
+	if imported != 1 || skipped != 2 {
+		t.Errorf("ImportJSON() = %d importadas, %d omitidas; se esperaba 1 y 2", imported, skipped)
+	}
+
+	matches, err := store.Matches(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := matchIDs(matches); got != "m1,m2" || matches[0].Map != "Ascent" {
+		t.Errorf("Matches() = %s (m1 en %s), se esperaba m1,m2 con m1 sin cambios", got, matches[0].Map)
+	}
+	if rows, _ := store.PlayerMatches(Filter{Player: "Santi"}); len(rows) != 1 {
+		t.Errorf("filas de Santi = %d, se esperaba 1", len(rows))
+	}
+
+	// Importar de nuevo no agrega nada
+	if imported, skipped, _ := ImportJSON(store, path); imported != 0 || skipped != 3 {
+		t.Errorf("segunda importación = %d, %d; se esperaba 0 y 3", imported, skipped)
+	}
+
+	if _, _, err := ImportJSON(store, filepath.Join(t.TempDir(), "no-existe.json")); err == nil {
+		t.Error("ImportJSON() con un archivo inexistente no retornó error")
+	}
+}
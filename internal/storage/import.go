@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"valo-track/internal/models"
+)
+
+// LoadJSON carga un arreglo de partidas desde un archivo JSON (formato de matches.json)
+func LoadJSON(path string) ([]models.MatchData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.MatchData
+	if err := json.Unmarshal(data, &matches); err != nil {
+		return nil, fmt.Errorf("error decodificando %s: %w", path, err)
+	}
+
+	return matches, nil
+}
+
+// ImportJSON importa al store las partidas de un archivo JSON que todavía no estén guardadas.
+// Retorna la cantidad de partidas importadas y omitidas.
+func ImportJSON(store Store, path string) (imported, skipped int, err error) {
+	matches, err := LoadJSON(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pending := make([]models.MatchData, 0, len(matches))
+	for _, match := range matches {
+		if match.MatchID == "" {
+			skipped++
+			continue
+		}
+
+		exists, err := store.HasMatch(match.MatchID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if exists {
+			skipped++
+			continue
+		}
+		pending = append(pending, match)
+	}
+
+	if len(pending) == 0 {
+		return 0, skipped, nil
+	}
+
+	if err := store.SaveMatches(pending); err != nil {
+		return 0, skipped, err
+	}
+
+	return len(pending), skipped, nil
+}
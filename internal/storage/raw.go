@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const rawExtension = ".json.gz"
+
+// RawArchive guarda el body crudo de cada respuesta de partida comprimido con gzip,
+// un archivo por MatchID, para poder recalcular análisis sin volver a descargar
+type RawArchive struct {
+	dir string
+}
+
+// NewRawArchive crea el archivo de respuestas crudas en el directorio indicado
+func NewRawArchive(dir string) (*RawArchive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de archivo crudo: %w", err)
+	}
+	return &RawArchive{dir: dir}, nil
+}
+
+// SaveRaw guarda el body de una partida. Escribe a un archivo temporal y lo renombra
+// para no dejar archivos truncados si el proceso se interrumpe.
+func (ra *RawArchive) SaveRaw(matchID string, body []byte) error {
+	path, err := ra.path(matchID)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(ra.dir, ".raw-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if _, err := gz.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadRaw retorna el body descomprimido de una partida archivada
+func (ra *RawArchive) LoadRaw(matchID string) ([]byte, error) {
+	path, err := ra.path(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("error descomprimiendo %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	return io.ReadAll(gz)
+}
+
+// HasRaw indica si la partida está archivada
+func (ra *RawArchive) HasRaw(matchID string) bool {
+	path, err := ra.path(matchID)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// List retorna los MatchID archivados ordenados alfabéticamente
+func (ra *RawArchive) List() ([]string, error) {
+	entries, err := os.ReadDir(ra.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, rawExtension) {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, rawExtension))
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// path construye la ruta del archivo de una partida validando el MatchID
+func (ra *RawArchive) path(matchID string) (string, error) {
+	if matchID == "" || matchID != filepath.Base(matchID) || strings.HasPrefix(matchID, ".") {
+		return "", fmt.Errorf("MatchID inválido para archivar: %q", matchID)
+	}
+	return filepath.Join(ra.dir, matchID+rawExtension), nil
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"strings"
+	"time"
+	"valo-track/internal/models"
+)
+
+// Store define el almacenamiento consultable de partidas
+type Store interface {
+	// SaveMatch guarda (o reemplaza) una partida junto con sus filas derivadas
+	SaveMatch(match models.MatchData) error
+	// SaveMatches guarda varias partidas en una sola transacción
+	SaveMatches(matches []models.MatchData) error
+	// HasMatch indica si la partida ya está guardada
+	HasMatch(matchID string) (bool, error)
+	// Count retorna la cantidad de partidas guardadas
+	Count() (int, error)
+
+	// MarkDiscarded registra una partida descartada por el análisis (por ejemplo, sin el
+	// stack) para no volver a descargarla. Guardarla después la saca del registro.
+	MarkDiscarded(matchID string) error
+	// IsDiscarded indica si la partida se descartó antes y no se guardó desde entonces
+	IsDiscarded(matchID string) (bool, error)
+
+	// Matches retorna las partidas que cumplen el filtro, ordenadas por fecha
+	Matches(filter Filter) ([]models.MatchData, error)
+	// PlayerMatches retorna una fila por jugador del stack y partida
+	PlayerMatches(filter Filter) ([]PlayerMatchRow, error)
+	// Rounds retorna las rondas de las partidas que cumplen el filtro
+	Rounds(filter Filter) ([]RoundRow, error)
+	// Kills retorna los eventos de muerte de las partidas que cumplen el filtro
+	Kills(filter Filter) ([]KillRow, error)
+
+	Close() error
+}
+
+// Filter define los criterios de búsqueda. Los campos vacíos no filtran.
+type Filter struct {
+	From   time.Time // Inclusive
+	To     time.Time // Exclusive
+	Map    string
+	Agent  string
+	Queue  string
+	Player string // Nombre real del jugador del stack
+}
+
+// PlayerMatchRow contiene los stats de un jugador del stack en una partida
+type PlayerMatchRow struct {
+	MatchID   string
+	Player    string
+	Timestamp int64
+	Map       string
+	Queue     string
+	Won       bool
+	Rounds    int
+	models.PlayerMatchStats
+	FirstKills     int
+	FirstDeaths    int
+	KASTRounds     int
+	Clutches       int
+	ClutchAttempts int
+}
+
+// RoundRow contiene una ronda junto con el contexto de su partida
+type RoundRow struct {
+	MatchID   string
+	Timestamp int64
+	Map       string
+	models.RoundData
+}
+
+// KillRow contiene un evento de muerte junto con el contexto de su partida
+type KillRow struct {
+	MatchID   string
+	Timestamp int64
+	Map       string
+	Index     int // Orden del evento dentro de la partida
+	models.KillEvent
+}
+
+// matchesTime verifica si un timestamp (segundos Unix) está dentro del rango del filtro
+func (f Filter) matchesTime(timestamp int64) bool {
+	if !f.From.IsZero() && timestamp < f.From.Unix() {
+		return false
+	}
+	if !f.To.IsZero() && timestamp >= f.To.Unix() {
+		return false
+	}
+	return true
+}
+
+// matchesMatch verifica si una partida cumple el filtro
+func (f Filter) matchesMatch(match *models.MatchData) bool {
+	if !f.matchesTime(match.Timestamp) {
+		return false
+	}
+	if f.Map != "" && !strings.EqualFold(f.Map, match.Map) {
+		return false
+	}
+	if f.Queue != "" && !strings.EqualFold(f.Queue, match.Mode) {
+		return false
+	}
+
+	if f.Player != "" {
+		stats, ok := match.PlayerData[f.Player]
+		if !ok {
+			return false
+		}
+		return f.Agent == "" || strings.EqualFold(f.Agent, stats.Agent)
+	}
+
+	if f.Agent != "" {
+		for _, stats := range match.PlayerData {
+			if strings.EqualFold(f.Agent, stats.Agent) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// matchesPlayerRow verifica si una fila de jugador cumple el filtro
+func (f Filter) matchesPlayerRow(row *PlayerMatchRow) bool {
+	if f.Player != "" && f.Player != row.Player {
+		return false
+	}
+	return f.Agent == "" || strings.EqualFold(f.Agent, row.Agent)
+}
+
+// matchesKill verifica si un evento de muerte involucra al jugador del filtro
+func (f Filter) matchesKill(kill *models.KillEvent) bool {
+	return f.Player == "" || kill.KillerName == f.Player || kill.VictimName == f.Player
+}
+
+// BuildPlayerRows genera las filas por jugador de una partida
+func BuildPlayerRows(match *models.MatchData) []PlayerMatchRow {
+	rows := make([]PlayerMatchRow, 0, len(match.PlayerData))
+	for player, stats := range match.PlayerData {
+		rows = append(rows, PlayerMatchRow{
+			MatchID:          match.MatchID,
+			Player:           player,
+			Timestamp:        match.Timestamp,
+			Map:              match.Map,
+			Queue:            match.Mode,
+			Won:              match.Won,
+			Rounds:           match.RoundsPlayed,
+			PlayerMatchStats: stats,
+			FirstKills:       match.FirstKills[player],
+			FirstDeaths:      match.FirstDeaths[player],
+			KASTRounds:       match.KASTRounds[player],
+			Clutches:         match.Clutches[player],
+			ClutchAttempts:   clutchAttempts(match, player),
+		})
+	}
+	return rows
+}
+
+// clutchAttempts suma las situaciones de clutch de un jugador en la partida
+func clutchAttempts(match *models.MatchData, player string) int {
+	total := 0
+	for _, count := range match.ClutchAttempts[player] {
+		total += count
+	}
+	return total
+}